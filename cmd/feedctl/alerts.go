@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/terratensor/feed-parser/internal/alerts"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/storage/manticore"
+)
+
+const alertsUsage = "usage: feedctl alerts add|list|delete [flags]"
+
+func runAlerts(args []string) error {
+	if len(args) == 0 {
+		return errors.New(alertsUsage)
+	}
+
+	switch args[0] {
+	case "add":
+		return runAlertsAdd(args[1:])
+	case "list":
+		return runAlertsList(args[1:])
+	case "delete":
+		return runAlertsDelete(args[1:])
+	default:
+		return errors.New(alertsUsage)
+	}
+}
+
+func runAlertsAdd(args []string) error {
+	var configPath string
+	var s alerts.Subscription
+
+	fs := newFlagSet("alerts add", &configPath)
+	fs.StringVar(&s.Query, "query", "", "полнотекстовый запрос в синтаксисе мантикоры")
+	fs.StringVar(&s.Webhook, "webhook", "", "имя вебхука из раздела alerts.webhooks")
+	fs.IntVar(&s.ResourceID, "resource-id", 0, "идентификатор ресурса, 0 — все ресурсы")
+	fs.StringVar(&s.Language, "lang", "", "язык записей, по умолчанию все языки")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if s.Query == "" || s.Webhook == "" {
+		return errors.New("usage: feedctl alerts add --query <query> --webhook <name> [--resource-id id] [--lang lang]")
+	}
+
+	cfg, store, err := openAlertStore(configPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Alerts.Webhook(s.Webhook); !ok {
+		return fmt.Errorf("webhook %q not found in config", s.Webhook)
+	}
+
+	if err := store.Add(context.Background(), &s); err != nil {
+		return err
+	}
+	fmt.Printf("alert %d added\n", s.ID)
+	return nil
+}
+
+func runAlertsList(args []string) error {
+	var configPath string
+
+	fs := newFlagSet("alerts list", &configPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, store, err := openAlertStore(configPath)
+	if err != nil {
+		return err
+	}
+
+	subs, err := store.List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(subs)
+}
+
+func runAlertsDelete(args []string) error {
+	var configPath string
+
+	fs := newFlagSet("alerts delete", &configPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: feedctl alerts delete <id>")
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid alert id %q", fs.Arg(0))
+	}
+
+	_, store, err := openAlertStore(configPath)
+	if err != nil {
+		return err
+	}
+	return store.Delete(context.Background(), id)
+}
+
+// openAlertStore загружает конфигурацию и открывает таблицу сохраненных запросов alerts.table
+func openAlertStore(configPath string) (*config.Config, *manticore.AlertStore, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := manticore.NewAlertStore(cfg.Alerts.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, store, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+func runBackfill(args []string) error {
+	var configPath, source, metricsAddr, startDate, stopDate, checkpointDir string
+	var maxPages int
+
+	fs := newFlagSet("backfill", &configPath)
+	fs.StringVar(&source, "source", "", "источник: kremlin, mid или mil")
+	fs.StringVar(&startDate, "start-date", "", "самая ранняя дата публикации записей (2006-01-02), по умолчанию из конфигурации")
+	fs.StringVar(&stopDate, "stop-date", "", "самая поздняя дата публикации записей (2006-01-02), по умолчанию из конфигурации")
+	fs.IntVar(&maxPages, "max-pages", -1, "максимальное количество страниц за запуск, 0 — без ограничения, по умолчанию из конфигурации")
+	fs.StringVar(&checkpointDir, "checkpoint-dir", "", "каталог для сохранения позиций обхода, по умолчанию из конфигурации")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", "адрес http сервера метрик prometheus, если пустой, сервер не запускается")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if source == "" {
+		return errors.New("--source is required")
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if startDate != "" {
+		cfg.Backfill.StartDate = startDate
+	}
+	if stopDate != "" {
+		cfg.Backfill.StopDate = stopDate
+	}
+	if maxPages >= 0 {
+		cfg.Backfill.MaxPages = maxPages
+	}
+	if checkpointDir != "" {
+		cfg.Backfill.CheckpointDir = checkpointDir
+	}
+
+	m := metrics.NewMetrics()
+	if metricsAddr != "" {
+		m.Register()
+		app.StartMetricsServer(metricsAddr)
+	}
+
+	return app.RunBackfill(cfg, source, m)
+}
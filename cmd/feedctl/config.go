@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return errors.New("usage: feedctl config validate [--config path]")
+	}
+
+	var configPath string
+
+	fs := newFlagSet("config validate", &configPath)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config %v is invalid:\n%v", configPath, err)
+	}
+
+	fmt.Printf("config %v is valid, %d parsers\n", configPath, len(cfg.Parsers))
+	return nil
+}
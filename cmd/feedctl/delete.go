@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/events"
+)
+
+func runDelete(args []string) error {
+	var configPath, index, url string
+
+	fs := newFlagSet("delete", &configPath)
+	fs.StringVar(&index, "index", "", "таблица мантикоры, по умолчанию manticore_index из конфигурации")
+	fs.StringVar(&url, "url", "", "url записи, все фрагменты которой будут удалены")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if url == "" {
+		return errors.New("--url is required")
+	}
+
+	var cfg *config.Config
+	if configPath != "" {
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return err
+		}
+	}
+	entries, err := openStorage(cfg, index)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	chunks, err := entries.Storage.FindAllByUrl(ctx, url)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return fmt.Errorf("entry %v not found", url)
+	}
+
+	for _, chunk := range chunks {
+		if err := entries.Storage.Delete(ctx, chunk.ID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("deleted %d chunks of %v", len(chunks), url)
+
+	// Событие удаления записывается в журнал, подписчикам его доставит служба
+	if cfg != nil && cfg.Events.Enabled {
+		outbox, err := events.OpenOutbox(cfg.Events.Dir, cfg.Events.Retention)
+		if err != nil {
+			return err
+		}
+		return events.NewBus(outbox, cfg.Events.MaxAttempts).Publish(ctx, events.Event{Type: events.EntryDeleted, Entry: events.FromChunks(chunks)})
+	}
+	return nil
+}
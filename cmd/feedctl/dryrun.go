@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/parser"
+	"github.com/terratensor/feed-parser/internal/transcript"
+	"github.com/terratensor/feed-parser/internal/workerpool"
+)
+
+// dryRunResult запись после разбора ленты и обхода краулером вместе с фрагментами,
+// на которые ее разбивает сплиттер, и репликами, если контент является стенограммой
+type dryRunResult struct {
+	Entry  feed.Entry        `json:"entry"`
+	Chunks []feed.Entry      `json:"chunks"`
+	Turns  []transcript.Turn `json:"turns,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+func runDryRun(args []string) error {
+	var configPath, lang, fixturesDir string
+	var resourceID, limit int
+	var noCrawl bool
+
+	fs := newFlagSet("dry-run", &configPath)
+	fs.StringVar(&lang, "lang", "", "язык ленты, если url нет в конфигурации")
+	fs.IntVar(&resourceID, "resource-id", 0, "идентификатор ресурса, если url нет в конфигурации")
+	fs.IntVar(&limit, "limit", 0, "обработать только первые N записей ленты, 0 — все")
+	fs.BoolVar(&noCrawl, "no-crawl", false, "не запускать краулер для записей")
+	fs.StringVar(&fixturesDir, "fixtures", "", "каталог для сохранения тел полученных ответов в качестве фикстур")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: feedctl dry-run [flags] <url>")
+	}
+	url := fs.Arg(0)
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	parserCfg, err := findParserConfig(cfg, url, lang, resourceID)
+	if err != nil {
+		return err
+	}
+	// Краулер ищет свою конфигурацию среди парсеров по ресурсу и языку,
+	// поэтому ленту, которой нет в конфигурации, добавляем в копию конфигурации
+	dryCfg := *cfg
+	dryCfg.Parsers = append([]config.Parser{parserCfg}, cfg.Parsers...)
+
+	if fixturesDir != "" {
+		rec, err := newRecorder(http.DefaultTransport, fixturesDir)
+		if err != nil {
+			return err
+		}
+		// Все http клиенты парсеров и краулеров используют транспорт по умолчанию
+		http.DefaultTransport = rec
+	}
+
+	// Метрики не регистрируются, но нужны парсеру и краулеру
+	m := metrics.NewMetrics()
+
+	fp := gofeed.NewParser()
+	fp.UserAgent = cfg.UserAgent
+
+	entries := parser.NewParser(parserCfg, dryCfg, m).Fetch(fp)
+	log.Printf("fetched %d entries from %v", len(entries), url)
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	sp := app.NewChunker(cfg.SplitterFor(parserCfg))
+	entityTagger := app.NewTagger(cfg)
+
+	var results []dryRunResult
+	for _, entry := range entries {
+		e := &entry
+		result := dryRunResult{}
+
+		if !noCrawl {
+			ce, err := workerpool.VisitUrl(e, &dryCfg, m)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				e = ce
+			}
+		}
+
+		workerpool.Prepare(e)
+		workerpool.DetectLanguage(e, &dryCfg, m)
+		workerpool.MarkDuplicate(e, nil, m)
+		workerpool.TagEntry(e, entityTagger)
+		result.Entry = *e
+		result.Chunks = sp.SplitEntry(context.Background(), *e)
+		result.Turns = transcript.Parse(e.Content)
+		results = append(results, result)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(results)
+}
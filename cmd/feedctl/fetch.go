@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/parser"
+	"github.com/terratensor/feed-parser/internal/workerpool"
+)
+
+func runFetchOnce(args []string) error {
+	var configPath, lang string
+	var resourceID int
+
+	fs := newFlagSet("fetch-once", &configPath)
+	fs.StringVar(&lang, "lang", "", "язык ленты, если url нет в конфигурации")
+	fs.IntVar(&resourceID, "resource-id", 0, "идентификатор ресурса, если url нет в конфигурации")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: feedctl fetch-once [flags] <url>")
+	}
+	url := fs.Arg(0)
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	parserCfg, err := findParserConfig(cfg, url, lang, resourceID)
+	if err != nil {
+		return err
+	}
+
+	// Метрики не регистрируются, но нужны парсеру и обработчику задач
+	m := metrics.NewMetrics()
+
+	fp := gofeed.NewParser()
+	fp.UserAgent = cfg.UserAgent
+
+	entriesStore, err := openStorage(cfg, "")
+	if err != nil {
+		return err
+	}
+
+	p := parser.NewParser(parserCfg, *cfg, m).WithStorage(entriesStore)
+	entries := p.Fetch(fp)
+	log.Printf("fetched %d entries from %v", len(entries), url)
+	sp := app.NewChunker(cfg.SplitterFor(parserCfg))
+	dedupIndex := app.NewDedupIndex(cfg, entriesStore)
+	entityTagger := app.NewTagger(cfg)
+	alertNotifier := app.NewAlertNotifier(cfg, m)
+	// События только записываются в журнал, если он включен, доставку выполняет служба
+	indexNow := app.NewIndexNow(cfg)
+	bus := app.NewEventBus(cfg, indexNow, alertNotifier, app.NewTelegramPublisher(cfg, m))
+
+	var tasks []*workerpool.Task
+	for _, entry := range entries {
+		tasks = append(tasks, workerpool.NewTask(entry, sp, entriesStore, cfg, m).WithDedup(dedupIndex).WithTagger(entityTagger).WithEvents(bus))
+	}
+
+	workerpool.NewPool(tasks, cfg.Workers).Run()
+	if bus != nil {
+		bus.Close()
+	}
+	if indexNow != nil {
+		indexNow.Close()
+	}
+	if alertNotifier != nil {
+		alertNotifier.Close()
+	}
+	return nil
+}
+
+// findParserConfig ищет ленту url в конфигурации, если ленты нет,
+// то создает конфигурацию из переданных языка и идентификатора ресурса
+func findParserConfig(cfg *config.Config, url string, lang string, resourceID int) (config.Parser, error) {
+	for _, p := range cfg.Parsers {
+		if p.Url == url {
+			return p, nil
+		}
+	}
+
+	if lang == "" || resourceID == 0 {
+		return config.Parser{}, fmt.Errorf("url %v not found in config, --lang and --resource-id are required", url)
+	}
+
+	return config.Parser{
+		Url:        url,
+		Lang:       lang,
+		ResourceID: resourceID,
+		UserAgent:  cfg.UserAgent,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/rssfeed"
+	"github.com/terratensor/feed-parser/internal/tagger"
+)
+
+func runGenerate(args []string) error {
+	var configPath, index, dir, dictionary, baseURL string
+	var hubs []string
+	var duration, delay time.Duration
+
+	fs := newFlagSet("generate", &configPath)
+	fs.StringVar(&index, "index", os.Getenv("MANTICORE_INDEX"), "таблица мантикоры, по умолчанию manticore_index из конфигурации")
+	fs.StringVar(&dir, "dir", "./static", "каталог для сохранения фидов")
+	fs.DurationVar(&duration, "duration", 24*8*time.Hour, "за какой период попадают записи в фиды")
+	fs.StringVar(&dictionary, "dictionary", os.Getenv("TAGGER_DICTIONARY"), "словарь сущностей, по которому создаются фиды tags/<slug>.xml")
+	fs.StringArrayVar(&hubs, "hub", nil, "хаб WebSub, которому сообщается об изменении фидов, можно указать несколько раз")
+	fs.StringVar(&baseURL, "base-url", os.Getenv("FEED_BASE_URL"), "адрес публикации фидов для ссылок rel=\"self\", по умолчанию "+rssfeed.DefaultBaseURL)
+	fs.DurationVar(&delay, "delay", 0, "если больше 0, фиды пересоздаются в цикле с этой задержкой")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	entries, err := storageFromFlags(configPath, index)
+	if err != nil {
+		return err
+	}
+
+	generator := rssfeed.NewGenerator(entries, duration, dir).WithWebSub(hubs, baseURL)
+	if dictionary != "" {
+		dict, err := tagger.LoadDictionary(dictionary)
+		if err != nil {
+			return err
+		}
+		generator.WithTagFeeds(dict.Entities)
+	}
+
+	for {
+		count, err := generator.Generate(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("🚩 Созданы RSS-фиды. Всего записей: %d\n", count)
+
+		if delay <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// inspectResult фрагменты записи вместе с переводами записи на другие языки
+type inspectResult struct {
+	Chunks       []feed.Entry `json:"chunks"`
+	Translations []feed.Entry `json:"translations"`
+}
+
+func runInspect(args []string) error {
+	var configPath, index string
+	var translations bool
+
+	fs := newFlagSet("inspect", &configPath)
+	fs.StringVar(&index, "index", "", "таблица мантикоры, по умолчанию manticore_index из конфигурации")
+	fs.BoolVar(&translations, "translations", false, "вывести также переводы записи на другие языки")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: feedctl inspect [flags] <url>")
+	}
+
+	entries, err := storageFromFlags(configPath, index)
+	if err != nil {
+		return err
+	}
+
+	chunks, err := entries.Storage.FindAllByUrl(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return fmt.Errorf("entry %v not found", fs.Arg(0))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	if !translations {
+		return enc.Encode(chunks)
+	}
+
+	other, err := entries.Translations(context.Background(), chunks[0])
+	if err != nil {
+		return err
+	}
+	return enc.Encode(inspectResult{Chunks: chunks, Translations: other})
+}
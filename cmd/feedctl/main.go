@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	flag "github.com/spf13/pflag"
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// command описывает подкоманду feedctl
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"serve":      {usage: "запуск службы парсинга лент", run: runServe},
+	"generate":   {usage: "генерация RSS-фидов", run: runGenerate},
+	"reindex":    {usage: "перенос записей в новую таблицу с повторной разбивкой на фрагменты", run: runReindex},
+	"backfill":   {usage: "историческая индексация источника --source kremlin|mid|mil", run: runBackfill},
+	"dry-run":    {usage: "разбор ленты <url> и обход краулером без записи в мантикору, результат в формате JSON", run: runDryRun},
+	"fetch-once": {usage: "однократный опрос ленты <url> с сохранением записей", run: runFetchOnce},
+	"inspect":    {usage: "вывод сохраненных фрагментов записи <url> в формате JSON", run: runInspect},
+	"delete":     {usage: "удаление всех фрагментов записи --url", run: runDelete},
+	"search":     {usage: "поиск фрагментов с упоминанием сущностей --tag <slug>, результат в формате JSON", run: runSearch},
+	"stats":      {usage: "количество записей по ресурсам и языкам", run: runStats},
+	"alerts":     {usage: "сохраненные запросы оповещений: alerts add|list|delete", run: runAlerts},
+	"config":     {usage: "работа с конфигурацией: config validate", run: runConfig},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatalf("%s: %v", name, err)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: feedctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].usage)
+	}
+}
+
+// newFlagSet создает набор флагов подкоманды с общим флагом --config
+func newFlagSet(name string, configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVarP(configPath, "config", "c", os.Getenv("CONFIG_PATH"), "путь до конфиг-файла, по умолчанию из переменной CONFIG_PATH")
+	return fs
+}
+
+// loadConfig загружает конфигурацию по пути configPath
+func loadConfig(configPath string) (*config.Config, error) {
+	if configPath == "" {
+		return nil, errors.New("config path is not set, use --config or CONFIG_PATH environment variable")
+	}
+	return config.Load(configPath)
+}
+
+// openStorage открывает хранилище записей, если index пустой, то используется manticore_index из конфигурации
+func openStorage(cfg *config.Config, index string) (*feed.Entries, error) {
+	if index == "" && cfg != nil {
+		index = cfg.ManticoreIndex
+	}
+	if index == "" {
+		index = "feed"
+	}
+	return app.OpenEntriesStorage(index)
+}
+
+// storageFromFlags открывает хранилище записей по флагам --config и --index,
+// конфигурация в этом случае необязательна
+func storageFromFlags(configPath string, index string) (*feed.Entries, error) {
+	var cfg *config.Config
+	if configPath != "" {
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return openStorage(cfg, index)
+}
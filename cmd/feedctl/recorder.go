@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// recorder сохраняет тела всех полученных http ответов в каталог dir,
+// используется для подготовки тестовых фикстур
+type recorder struct {
+	next http.RoundTripper
+	dir  string
+
+	mu    sync.Mutex
+	count int
+}
+
+func newRecorder(next http.RoundTripper, dir string) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{next: next, dir: dir}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.count++
+	name := fmt.Sprintf("%03d_%s%s", r.count, fixtureName(req), fixtureExt(resp))
+	r.mu.Unlock()
+
+	filename := filepath.Join(r.dir, name)
+	if err := os.WriteFile(filename, body, 0o644); err != nil {
+		log.Printf("failed to save fixture %v: %v", filename, err)
+	} else {
+		log.Printf("fixture saved: %v <- %v", filename, req.URL)
+	}
+
+	return resp, nil
+}
+
+// fixtureName формирует безопасное имя файла из адреса запроса
+func fixtureName(req *http.Request) string {
+	name := req.URL.Host + req.URL.Path
+	if req.URL.RawQuery != "" {
+		name += "_" + req.URL.RawQuery
+	}
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 120 {
+		name = name[:120]
+	}
+	return name
+}
+
+// fixtureExt подбирает расширение файла по типу содержимого ответа
+func fixtureExt(resp *http.Response) string {
+	ct := resp.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(ct, "json"):
+		return ".json"
+	case strings.Contains(ct, "xml"), strings.Contains(ct, "rss"), strings.Contains(ct, "atom"):
+		return ".xml"
+	case strings.Contains(ct, "html"):
+		return ".html"
+	default:
+		return ".body"
+	}
+}
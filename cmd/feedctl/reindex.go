@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/splitter"
+)
+
+func runReindex(args []string) error {
+	var configPath, from, to string
+	var optParSize, maxParSize int
+	var copyTable bool
+
+	fs := newFlagSet("reindex", &configPath)
+	fs.StringVar(&from, "from", "feed", "таблица, из которой читаются записи")
+	fs.StringVar(&to, "to", "feed_new", "таблица, в которую записываются фрагменты")
+	fs.IntVarP(&optParSize, "optParSize", "o", 0, "граница оптимального размера параграфа в символах, по умолчанию из конфигурации или 1800")
+	fs.IntVarP(&maxParSize, "maxParSize", "m", 0, "граница максимального размера параграфа в символах, по умолчанию из конфигурации или 3600")
+	fs.BoolVar(&copyTable, "copy", false, "только копирование записей в новую таблицу без разбивки")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sc := config.Splitter{OptChunkSize: 1800, MaxChunkSize: 3600, OverlapUnit: splitter.OverlapChars, Strategy: splitter.StrategySize}
+	if configPath != "" {
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		sc = cfg.Splitter
+	}
+	if optParSize > 0 {
+		sc.OptChunkSize = optParSize
+	}
+	if maxParSize > 0 {
+		sc.MaxChunkSize = maxParSize
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	defer func() {
+		log.Printf("🚩 выполнено за: %v\n", time.Since(start))
+	}()
+
+	return app.Reindex(ctx, from, to, app.NewChunker(sc), copyTable)
+}
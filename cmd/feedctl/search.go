@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/tagger"
+)
+
+func runSearch(args []string) error {
+	var configPath, index, dictionary, query string
+	var tags []string
+	var limit int
+
+	fs := newFlagSet("search", &configPath)
+	fs.StringVar(&index, "index", "", "таблица мантикоры, по умолчанию manticore_index из конфигурации")
+	fs.StringVar(&dictionary, "dictionary", "", "словарь сущностей, по умолчанию tagger.dictionary из конфигурации")
+	fs.StringArrayVar(&tags, "tag", nil, "короткое имя сущности из словаря, можно указать несколько раз")
+	fs.StringVar(&query, "query", "", "полнотекстовый запрос")
+	fs.IntVar(&limit, "limit", 100, "максимальное количество фрагментов")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return errors.New("usage: feedctl search --tag <slug> [--tag <slug>] [--query <text>]")
+	}
+
+	if dictionary == "" {
+		if configPath == "" {
+			return errors.New("dictionary is not set, use --dictionary or --config")
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		dictionary = cfg.Tagger.Dictionary
+	}
+
+	dict, err := tagger.LoadDictionary(dictionary)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(tags))
+	for _, slug := range tags {
+		e, ok := dict.BySlug(slug)
+		if !ok {
+			return fmt.Errorf("unknown tag %q", slug)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	entries, err := storageFromFlags(configPath, index)
+	if err != nil {
+		return err
+	}
+
+	found, err := entries.Storage.FindByTags(context.Background(), ids, query, limit)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(found)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/app"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+func runServe(args []string) error {
+	var configPath, metricsAddr string
+
+	fs := newFlagSet("serve", &configPath)
+	fs.StringVar(&metricsAddr, "metrics-addr", ":8080", "адрес http сервера метрик prometheus")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	tnow := time.Now()
+	tz, _ := tnow.Zone()
+	log.Printf("Local time zone %s. Service started at %s", tz, tnow.Format("2006-01-02T15:04:05.000 MST"))
+
+	m := metrics.NewMetrics()
+	m.Register()
+
+	app.StartMetricsServer(metricsAddr)
+	app.RunService(cfg, m)
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/terratensor/feed-parser/internal/storage/manticore"
+)
+
+func runStats(args []string) error {
+	var configPath, index string
+
+	fs := newFlagSet("stats", &configPath)
+	fs.StringVar(&index, "index", "", "таблица мантикоры, по умолчанию manticore_index из конфигурации")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := storageFromFlags(configPath, index)
+	if err != nil {
+		return err
+	}
+
+	client, ok := entries.Storage.(*manticore.Client)
+	if !ok {
+		return fmt.Errorf("stats are not supported by storage %T", entries.Storage)
+	}
+
+	stats, err := client.Stats(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tLANGUAGE\tENTRIES\tCHUNKS")
+
+	var totalEntries, totalChunks int
+	for _, s := range stats {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", s.ResourceID, s.Language, s.Entries, s.Chunks)
+		totalEntries += s.Entries
+		totalChunks += s.Chunks
+	}
+	fmt.Fprintf(w, "total\t\t%d\t%d\n", totalEntries, totalChunks)
+
+	return w.Flush()
+}
@@ -2,55 +2,27 @@ package main
 
 import (
 	"log"
-	"sync"
+	"time"
 
-	"github.com/mmcdole/gofeed"
 	"github.com/terratensor/feed-parser/internal/app"
 	"github.com/terratensor/feed-parser/internal/config"
-	"github.com/terratensor/feed-parser/internal/entities/feed"
-	"github.com/terratensor/feed-parser/internal/indexer/kremlin"
-	"github.com/terratensor/feed-parser/internal/model/link"
-	"github.com/terratensor/feed-parser/internal/splitter"
-	"github.com/terratensor/feed-parser/internal/workerpool"
+	"github.com/terratensor/feed-parser/internal/metrics"
 )
 
 func main() {
 	cfg := config.MustLoad()
 
-	fp := gofeed.NewParser()
-	//fp.UserAgent = "PostmanRuntime/7.36.3"
-	ch := make(chan feed.Entry, cfg.EntryChanBuffer)
+	// output current time zone
+	tnow := time.Now()
+	tz, _ := tnow.Zone()
+	log.Printf("Local time zone %s. Indexer started at %s", tz, tnow.Format("2006-01-02T15:04:05.000 MST"))
 
-	wg := &sync.WaitGroup{}
-	for _, url := range cfg.Parsers {
+	// Создаем метрики и запускаем сервер для метрик
+	m := metrics.NewMetrics()
+	m.Register()
+	app.StartMetricsServer(":8080")
 
-		wg.Add(1)
-		kremlinIndexer := kremlin.NewIndexer(link.Link{
-			Url:        url.Url,
-			Lang:       url.Lang,
-			ResourceID: url.ResourceID,
-		}, *cfg.Delay, *cfg.RandomDelay)
-
-		go kremlinIndexer.Run(ch, fp, wg)
+	if err := app.RunBackfill(cfg, app.SourceKremlin, m); err != nil {
+		log.Fatal(err)
 	}
-
-	var allTask []*workerpool.Task
-
-	pool := workerpool.NewPool(allTask, cfg.Workers)
-	sp := splitter.NewSplitter(cfg.Splitter.OptChunkSize, cfg.Splitter.MaxChunkSize)
-	entriesStore := app.NewEntriesStorage(cfg.ManticoreIndex)
-
-	go func() {
-		for {
-			task := workerpool.NewTask(func(data interface{}) error {
-				return nil
-			}, <-ch, sp, entriesStore, cfg, nil)
-			pool.AddTask(task)
-		}
-	}()
-
-	pool.RunBackground()
-
-	wg.Wait()
-	log.Println("Indexer finished, all workers successfully stopped.")
 }
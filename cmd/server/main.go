@@ -4,11 +4,17 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/terratensor/feed-parser/internal/events"
+	"github.com/terratensor/feed-parser/internal/stream"
+	"github.com/terratensor/feed-parser/internal/websub"
 )
 
 // Метрики Prometheus
@@ -56,6 +62,43 @@ func handlerMilFeed(w http.ResponseWriter, r *http.Request) {
 	serveRssFile(w, r, "./static/mil.xml")
 }
 
+// Обработчик для фидов записей с упоминанием сущностей /tags/<slug>.xml
+func handlerTagFeed(w http.ResponseWriter, r *http.Request) {
+	name := path.Base(r.URL.Path)
+	if !strings.HasSuffix(name, ".xml") || strings.HasPrefix(name, ".") {
+		http.NotFound(w, r)
+		return
+	}
+	serveRssFile(w, r, "./static/tags/"+name)
+}
+
+// Обработчик файла ключа IndexNow /<key>.txt, по которому поисковые системы проверяют владельца сайта
+func handlerIndexNowKey(key string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(key))
+	}
+}
+
+// feedContent возвращает содержимое фида из каталога ./static по адресу фида topic на сайте base
+func feedContent(base string) websub.Content {
+	return func(topic string) ([]byte, string, bool, error) {
+		u, err := url.Parse(topic)
+		if err != nil || !strings.HasPrefix(topic, base+"/") {
+			return nil, "", false, nil
+		}
+		name := path.Clean(u.Path)
+		if !strings.HasSuffix(name, ".xml") || strings.Contains(name, "..") {
+			return nil, "", false, nil
+		}
+		data, err := os.ReadFile("./static" + name)
+		if os.IsNotExist(err) {
+			return nil, "", false, nil
+		}
+		return data, "application/rss+xml", err == nil, err
+	}
+}
+
 // serveRssFile читает файл и отправляет его как ответ
 func serveRssFile(w http.ResponseWriter, r *http.Request, filename string) {
 	file, err := os.Open(filename)
@@ -111,6 +154,37 @@ func main() {
 	mux.Handle("/kremlin.xml", logMiddleware(http.HandlerFunc(handlerKremlinFeed), logger))
 	mux.Handle("/mid.xml", logMiddleware(http.HandlerFunc(handlerMidFeed), logger))
 	mux.Handle("/mil.xml", logMiddleware(http.HandlerFunc(handlerMilFeed), logger))
+	mux.Handle("/tags/", logMiddleware(http.HandlerFunc(handlerTagFeed), logger))
+
+	// Хаб WebSub для подписки на обновления фидов
+	if os.Getenv("WEBSUB_HUB") != "" {
+		base := strings.TrimRight(envOr("FEED_BASE_URL", "https://rss.feed.svodd.ru"), "/")
+		hub, err := websub.NewHub(envOr("WEBSUB_HUB_URL", base+"/websub"), feedContent(base), os.Getenv("WEBSUB_STATE"))
+		if err != nil {
+			log.Fatalf("failed to initialize websub hub: %v", err)
+		}
+		mux.Handle("/websub", logMiddleware(hub, logger))
+		log.Println("WebSub hub enabled at /websub")
+	}
+
+	// Поток новых и обновленных записей из журнала событий службы
+	if dir := os.Getenv("EVENTS_DIR"); dir != "" {
+		poll, err := time.ParseDuration(envOr("STREAM_POLL", "1s"))
+		if err != nil {
+			log.Fatalf("invalid STREAM_POLL: %v", err)
+		}
+		outbox, err := events.OpenOutbox(dir, 0)
+		if err != nil {
+			log.Fatalf("failed to open events outbox: %v", err)
+		}
+		mux.Handle("/api/stream", logMiddleware(stream.NewHandler(outbox, poll), logger))
+		log.Println("Entry stream enabled at /api/stream")
+	}
+
+	// Файл ключа IndexNow
+	if key := os.Getenv("INDEX_NOW_KEY"); key != "" {
+		mux.Handle("/"+key+".txt", logMiddleware(handlerIndexNowKey(key), logger))
+	}
 
 	// Обработчик для статических файлов
 	fs := http.FileServer(http.Dir("./static"))
@@ -192,3 +266,16 @@ func (rw *responseWriterWrapper) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (rw *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// envOr возвращает значение переменной окружения name или def, если она не задана
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
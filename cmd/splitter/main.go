@@ -3,9 +3,8 @@ package main
 import (
 	"context"
 	flag "github.com/spf13/pflag"
-	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/app"
 	"github.com/terratensor/feed-parser/internal/splitter"
-	"github.com/terratensor/feed-parser/internal/storage/manticore"
 	"log"
 	"os"
 	"os/signal"
@@ -24,59 +23,13 @@ func main() {
 	flag.BoolVarP(&copyTable, "copy", "c", false, "только копирование записей в новую таблицу без разбивки")
 	flag.Parse()
 
-	// БД из которой читаем записи
-	manticoreClient, err := manticore.New("feed")
-	if err != nil {
-		log.Printf("failed to initialize manticore client: %v", err)
-		os.Exit(1)
-	}
-	entries := feed.NewFeedStorage(manticoreClient)
-
-	// БД в которую пишем разделенные на фрагменты записи
-	newManticoreClient, err := manticore.New("feed_new")
-	if err != nil {
-		log.Printf("failed to initialize manticore client: %v", err)
-		os.Exit(1)
-	}
-	splitEntries := feed.NewFeedStorage(newManticoreClient)
-
 	defer duration(track("🚩 выполнено за"))
 
-	ch, err := entries.FindAll(ctx, 10000)
-	if err != nil {
-		log.Fatalf("failed to find all entries: %v", err)
-	}
-
 	sp := splitter.NewSplitter(optParSize, maxParSize)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case e, ok := <-ch:
-			if !ok {
-				return
-			}
-
-			if copyTable {
-				id, err := splitEntries.Storage.Insert(ctx, &e)
-				if err != nil {
-					log.Fatalf("failed to insert entry: %v", err)
-				}
-				log.Printf("Entry inserted: %v", *id)
-			} else {
-				newEntries := sp.SplitEntry(ctx, e)
-
-				for _, newEntry := range newEntries {
-					_, err := splitEntries.Storage.Insert(ctx, &newEntry)
-					if err != nil {
-						log.Fatalf("failed to insert entry: %v", err)
-					}
-					//log.Printf("Entry inserted: %v", *id)
-				}
-			}
-
-		}
+	err := app.Reindex(ctx, "feed", "feed_new", sp, copyTable)
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
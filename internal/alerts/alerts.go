@@ -0,0 +1,86 @@
+// Package alerts проверяет новые и обновленные записи по сохраненным запросам
+// и отправляет оповещения о найденных записях на вебхуки
+package alerts
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+)
+
+// События, о которых отправляются оповещения
+const (
+	EventInserted = "inserted"
+	EventUpdated  = "updated"
+)
+
+// Subscription сохраненный запрос
+type Subscription struct {
+	ID         int64  `json:"id"`
+	Query      string `json:"query"`       // Полнотекстовый запрос в синтаксисе мантикоры
+	ResourceID int    `json:"resource_id"` // Ресурс записи, 0 — все ресурсы
+	Language   string `json:"language"`    // Язык записи, пустая строка — все языки
+	Webhook    string `json:"webhook"`     // Имя вебхука из конфигурации
+}
+
+// Document поля записи, по которым проверяются сохраненные запросы
+type Document struct {
+	Title      string `json:"title"`
+	Summary    string `json:"summary"`
+	Content    string `json:"content"`
+	ResourceID int    `json:"resource_id"`
+	Language   string `json:"language"`
+}
+
+// Store хранилище сохраненных запросов
+type Store interface {
+	Add(ctx context.Context, s *Subscription) error
+	List(ctx context.Context) ([]Subscription, error)
+	Delete(ctx context.Context, id int64) error
+	Match(ctx context.Context, doc Document) ([]Subscription, error)
+}
+
+// Alert оповещение, которое отправляется на вебхук
+type Alert struct {
+	Event        string       `json:"event"`
+	Subscription Subscription `json:"subscription"`
+	Entry        Entry        `json:"entry"`
+}
+
+// Entry запись в оповещении
+type Entry struct {
+	Url        string     `json:"url"`
+	Title      string     `json:"title"`
+	Summary    string     `json:"summary"`
+	Language   string     `json:"language"`
+	ResourceID int        `json:"resource_id"`
+	Published  *time.Time `json:"published"`
+	Tags       []int64    `json:"tags,omitempty"`
+}
+
+// NewDocument создает документ для проверки по сохраненным запросам из записи без html разметки
+func NewDocument(e feed.Entry) Document {
+	return Document{
+		Title:      e.Title,
+		Summary:    e.Summary,
+		Content:    strings.TrimSpace(html.UnescapeString(striphtml.StripHtmlTags(e.OwnContent()))),
+		ResourceID: e.ResourceID,
+		Language:   e.Language,
+	}
+}
+
+func newEntry(e feed.Entry) Entry {
+	return Entry{
+		Url:        e.Url,
+		Title:      e.Title,
+		Summary:    e.Summary,
+		Language:   e.Language,
+		ResourceID: e.ResourceID,
+		Published:  e.Published,
+		Tags:       e.Tags,
+	}
+}
@@ -0,0 +1,165 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// deliveryWorkers количество горутин, доставляющих оповещения
+const deliveryWorkers = 4
+
+// SignatureHeader заголовок с подписью HMAC-SHA256 тела запроса
+const SignatureHeader = "X-Feed-Signature"
+
+type delivery struct {
+	webhook config.Webhook
+	alert   Alert
+}
+
+// Notifier проверяет записи по сохраненным запросам и доставляет оповещения на вебхуки.
+// Доставка выполняется в фоне с повторными попытками, чтобы не задерживать обработку записей.
+type Notifier struct {
+	store   Store
+	cfg     config.Alerts
+	client  *http.Client
+	metrics *metrics.Metrics
+	queue   chan delivery
+	wg      sync.WaitGroup
+}
+
+// NewNotifier создает Notifier и запускает горутины доставки оповещений
+func NewNotifier(store Store, cfg config.Alerts, m *metrics.Metrics) *Notifier {
+	n := &Notifier{
+		store:   store,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		metrics: m,
+		queue:   make(chan delivery, cfg.QueueSize),
+	}
+	for i := 0; i < deliveryWorkers; i++ {
+		n.wg.Add(1)
+		go n.run()
+	}
+	return n
+}
+
+// Notify проверяет запись по сохраненным запросам и ставит оповещения в очередь доставки.
+// Если очередь заполнена, оповещение отбрасывается.
+func (n *Notifier) Notify(ctx context.Context, e feed.Entry, event string) error {
+	subs, err := n.store.Match(ctx, NewDocument(e))
+	if err != nil {
+		return fmt.Errorf("failed to match alerts for %v: %v", e.Url, err)
+	}
+	if len(subs) > 0 && n.metrics != nil {
+		n.metrics.AlertsMatched.WithLabelValues(strconv.Itoa(e.ResourceID)).Inc()
+	}
+
+	for _, s := range subs {
+		w, ok := n.cfg.Webhook(s.Webhook)
+		if !ok {
+			log.Printf("alert %d: unknown webhook %q", s.ID, s.Webhook)
+			continue
+		}
+		d := delivery{webhook: w, alert: Alert{Event: event, Subscription: s, Entry: newEntry(e)}}
+		select {
+		case n.queue <- d:
+		default:
+			log.Printf("alert %d: delivery queue is full, alert for %v dropped", s.ID, e.Url)
+			n.delivered(w.Name, "dropped")
+		}
+	}
+	return nil
+}
+
+// Close дожидается доставки оповещений из очереди, после Close вызывать Notify нельзя
+func (n *Notifier) Close() {
+	close(n.queue)
+	n.wg.Wait()
+}
+
+func (n *Notifier) run() {
+	defer n.wg.Done()
+	for d := range n.queue {
+		n.deliver(d)
+	}
+}
+
+// deliver отправляет оповещение, при ошибке повторяет попытки с удваивающейся задержкой
+func (n *Notifier) deliver(d delivery) {
+	body, err := json.Marshal(d.alert)
+	if err != nil {
+		log.Printf("alert %d: failed to marshal alert: %v", d.alert.Subscription.ID, err)
+		return
+	}
+
+	delay := n.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := n.send(d.webhook, body)
+		if err == nil {
+			n.delivered(d.webhook.Name, "ok")
+			return
+		}
+		if !retry || attempt >= n.cfg.Retries {
+			log.Printf("alert %d: failed to deliver %v to webhook %v: %v", d.alert.Subscription.ID, d.alert.Entry.Url, d.webhook.Name, err)
+			n.delivered(d.webhook.Name, "failed")
+			return
+		}
+		log.Printf("alert %d: webhook %v attempt %d failed: %v, retry in %v", d.alert.Subscription.ID, d.webhook.Name, attempt+1, err, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// send отправляет тело оповещения на вебхук и сообщает, имеет ли смысл повторить попытку
+func (n *Notifier) send(w config.Webhook, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(body, w.Secret))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// Ошибки клиента, кроме превышения лимита запросов, при повторе не исправятся
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %v", resp.Status)
+}
+
+func (n *Notifier) delivered(webhook string, status string) {
+	if n.metrics != nil {
+		n.metrics.AlertsDelivered.WithLabelValues(webhook, status).Inc()
+	}
+}
+
+// Sign возвращает подпись HMAC-SHA256 тела запроса в шестнадцатеричном виде
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/alerts"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/storage/manticore"
+)
+
+// NewAlertNotifier создает Notifier оповещений по сохраненным запросам из таблицы alerts.table.
+// Если оповещения выключены или таблица недоступна, возвращает nil.
+func NewAlertNotifier(cfg *config.Config, m *metrics.Metrics) *alerts.Notifier {
+	if !cfg.Alerts.Enabled {
+		return nil
+	}
+
+	store, err := manticore.NewAlertStore(cfg.Alerts.Table)
+	if err != nil {
+		log.Printf("failed to initialize alerts store: %v", err)
+		return nil
+	}
+	log.Printf("alerts enabled, table %v, %d webhooks", cfg.Alerts.Table, len(cfg.Alerts.Webhooks))
+
+	return alerts.NewNotifier(store, cfg.Alerts, m)
+}
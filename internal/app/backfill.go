@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/terratensor/feed-parser/internal/backfill"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/parser"
+	"github.com/terratensor/feed-parser/internal/workerpool"
+)
+
+// Источники, для которых реализована историческая индексация
+const (
+	SourceKremlin = "kremlin"
+	SourceMid     = "mid"
+	SourceMil     = "mil"
+)
+
+// sourceResources идентификаторы ресурсов источников
+var sourceResources = map[string]int{
+	SourceKremlin: 1,
+	SourceMid:     2,
+	SourceMil:     3,
+}
+
+// RunBackfill запускает историческую индексацию источника source для каждой ленты ресурса из конфигурации
+// в постраничном режиме парсера и сохраняет полученные записи в мантикору. Позиция обхода каждой ленты сохраняется
+// в каталоге backfill.checkpoint_dir, повторный запуск продолжает обход с последней сохраненной страницы.
+// Функция завершается, когда все ленты пройдены и все записи обработаны, или по сигналу прерывания.
+func RunBackfill(cfg *config.Config, source string, m *metrics.Metrics) error {
+	resourceID, ok := sourceResources[source]
+	if !ok {
+		return fmt.Errorf("unknown backfill source %q, expected one of: %s, %s, %s", source, SourceKremlin, SourceMid, SourceMil)
+	}
+
+	startDate, stopDate, err := cfg.Backfill.Dates()
+	if err != nil {
+		return err
+	}
+
+	store, err := backfill.NewFileStore(cfg.Backfill.CheckpointDir)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	entriesStore, err := OpenEntriesStorage(cfg.ManticoreIndex)
+	if err != nil {
+		return fmt.Errorf("failed to initialize manticore client: %v", err)
+	}
+	dedupIndex := NewDedupIndex(cfg, entriesStore)
+	entityTagger := NewTagger(cfg)
+
+	tasks := make(chan *workerpool.Task)
+
+	var errsMu sync.Mutex
+	var errs []error
+
+	var feeds int
+	wg := &sync.WaitGroup{}
+	for _, parserCfg := range cfg.Parsers {
+		if parserCfg.ResourceID != resourceID {
+			continue
+		}
+		feeds++
+
+		p := parser.NewParser(parserCfg, *cfg, m)
+		startUrl, err := p.StartPage()
+		if err != nil {
+			return fmt.Errorf("backfill %v: invalid feed url %v: %v", source, parserCfg.Url, err)
+		}
+		opts := backfill.Options{
+			StartDate:   startDate,
+			StopDate:    stopDate,
+			MaxPages:    cfg.Backfill.MaxPages,
+			MaxErrors:   cfg.Backfill.MaxErrors,
+			Delay:       p.Delay,
+			RandomDelay: p.RandomDelay,
+		}
+		runner := backfill.NewRunner(source, startUrl, p, store, opts, m)
+		// Записи ленты разбиваются на фрагменты с параметрами этой ленты
+		chunker := NewChunker(cfg.SplitterFor(parserCfg))
+		ch := make(chan backfill.Entry, cfg.EntryChanBuffer)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			defer close(ch)
+			if err := runner.Run(ctx, ch); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("backfill %v stopped with error: %v", runner.Key, err)
+				errsMu.Lock()
+				errs = append(errs, err)
+				errsMu.Unlock()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for e := range ch {
+				task := workerpool.NewTask(e.Entry, chunker, entriesStore, cfg, m).WithDedup(dedupIndex).WithTagger(entityTagger)
+				done := e.Done
+				task.WithDone(func() { done(task.Err) })
+				tasks <- task
+			}
+		}()
+	}
+
+	if feeds == 0 {
+		return fmt.Errorf("no feeds with resource_id %d found in config for backfill source %q", resourceID, source)
+	}
+
+	// Закрываем канал задач, когда все ленты пройдены
+	go func() {
+		wg.Wait()
+		close(tasks)
+	}()
+
+	workerpool.NewPool(nil, cfg.Workers).RunStream(tasks)
+
+	log.Println("Indexer finished, all workers successfully stopped.")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/dedup"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// NewDedupIndex создает индекс отпечатков и заполняет его записями, опубликованными
+// в пределах окна dedup.window. Если поиск копий выключен, возвращает nil.
+func NewDedupIndex(cfg *config.Config, entries *feed.Entries) *dedup.Index {
+	if !cfg.Dedup.Enabled {
+		return nil
+	}
+
+	index := dedup.NewIndex(cfg.Dedup.Threshold, cfg.Dedup.Window)
+
+	now := time.Now()
+	recent, err := entries.Storage.FindPublished(context.Background(), 0, now.Add(-cfg.Dedup.Window), now)
+	if err != nil {
+		log.Printf("failed to seed dedup index: %v", err)
+		return index
+	}
+	index.Seed(recent)
+	log.Printf("dedup index seeded with %d entries", len(recent))
+
+	return index
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/alerts"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/events"
+	"github.com/terratensor/feed-parser/internal/indexnow"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/telegram"
+)
+
+// NewEventBus создает шину событий изменения записей и подписывает на нее IndexNow, оповещения, публикацию в Telegram
+// и вебхуки из конфигурации, nil indexNow, notifier или publisher — без этих подписчиков. Доставку событий из журнала
+// запускает служба методом Start, остальные команды только записывают события в журнал. Если журнал включен, шина создается
+// и без подписчиков, потому что журнал читает поток записей /api/stream. Если журнал выключен и подписчиков нет, возвращает nil.
+func NewEventBus(cfg *config.Config, indexNow *indexnow.IndexNow, notifier *alerts.Notifier, publisher *telegram.Publisher) *events.Bus {
+	var outbox *events.Outbox
+	if cfg.Events.Enabled {
+		var err error
+		outbox, err = events.OpenOutbox(cfg.Events.Dir, cfg.Events.Retention)
+		if err != nil {
+			log.Fatalf("failed to open events outbox: %v", err)
+		}
+	}
+
+	bus := events.NewBus(outbox, cfg.Events.MaxAttempts)
+	subscribers := 0
+
+	if indexNow != nil && cfg.Env == "prod" {
+		bus.Subscribe("indexnow", indexNowHandler(indexNow, cfg.IndexNowOptions))
+		subscribers++
+	}
+	if notifier != nil {
+		bus.Subscribe("alerts", alertsHandler(notifier))
+		subscribers++
+	}
+	if publisher != nil {
+		bus.Subscribe("telegram", telegramHandler(publisher))
+		subscribers++
+	}
+	for _, w := range cfg.Events.Webhooks {
+		bus.Subscribe("webhook-"+w.Name, events.WebhookHandler(w, cfg.Events.Timeout))
+		subscribers++
+	}
+
+	if subscribers == 0 && outbox == nil {
+		return nil
+	}
+	return bus
+}
+
+// EntryExists проверяет, что в хранилище сохранена версия записи из отметки о событии,
+// для восстановления потерянных событий. Если обновление записи не удалось, в хранилище
+// остается прежняя версия с другим отпечатком фрагментов, и событие не публикуется.
+func EntryExists(store *feed.Entries) events.Exists {
+	return func(ctx context.Context, url string, hash string) (bool, error) {
+		chunks, err := store.Storage.FindAllByUrl(ctx, url)
+		if err != nil || len(chunks) == 0 {
+			return false, err
+		}
+		return hash == "" || events.ChunksHash(chunks) == hash, nil
+	}
+}
+
+// indexNowHandler ставит в очередь IndexNow публичные адреса новых записей, обновления и удаления не отправляются.
+// Адреса отправляются пакетами, поэтому событие считается обработанным после сохранения адреса в файл очереди,
+// из которого адрес удаляется только после успешной отправки.
+func indexNowHandler(indexNow *indexnow.IndexNow, opts config.IndexNowOptions) events.Handler {
+	return func(ctx context.Context, ev events.Event) error {
+		e := ev.Entry
+		if ev.Type != events.EntryCreated || e.Url == "" || !opts.Submits(e.Language) {
+			return nil
+		}
+		return indexNow.Submit(opts.EntryURL(e.Language, e.Url))
+	}
+}
+
+// alertsHandler проверяет новые и обновленные записи по сохраненным запросам оповещений.
+// Копии записей других источников не проверяются, чтобы не повторять оповещения.
+func alertsHandler(notifier *alerts.Notifier) events.Handler {
+	return func(ctx context.Context, ev events.Event) error {
+		if ev.Entry.DuplicateOf != "" {
+			return nil
+		}
+		switch ev.Type {
+		case events.EntryCreated:
+			return notifier.Notify(ctx, ev.Entry, alerts.EventInserted)
+		case events.EntryUpdated:
+			return notifier.Notify(ctx, ev.Entry, alerts.EventUpdated)
+		}
+		return nil
+	}
+}
+
+// telegramHandler публикует в Telegram новые записи. Обновления записей и копии записей других источников не публикуются.
+func telegramHandler(publisher *telegram.Publisher) events.Handler {
+	return func(ctx context.Context, ev events.Event) error {
+		if ev.Type != events.EntryCreated || ev.Entry.DuplicateOf != "" {
+			return nil
+		}
+		return publisher.Publish(ctx, ev.Entry)
+	}
+}
+
+// NewTelegramPublisher создает публикацию новых записей в Telegram, если она включена.
+// Если журнал публикаций не удалось прочитать, возвращает nil, чтобы не отправить записи повторно.
+func NewTelegramPublisher(cfg *config.Config, m *metrics.Metrics) *telegram.Publisher {
+	if !cfg.Telegram.Enabled {
+		return nil
+	}
+
+	publisher, err := telegram.NewPublisher(cfg.Telegram, m)
+	if err != nil {
+		log.Printf("failed to initialize telegram publisher: %v", err)
+		return nil
+	}
+	log.Printf("telegram publishing enabled, %d chats", len(cfg.Telegram.Chats))
+
+	return publisher
+}
+
+// NewIndexNow создает очередь отправки адресов записей в IndexNow, если индексация включена
+func NewIndexNow(cfg *config.Config) *indexnow.IndexNow {
+	// Передаем в конструктор indexNow параметр enabled инициализируем индексацию
+	return indexnow.NewIndexNow(cfg.IndexNow, cfg.IndexNowOptions)
+}
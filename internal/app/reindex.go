@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/splitter"
+	"github.com/terratensor/feed-parser/internal/workerpool"
+)
+
+// Reindex читает все записи из таблицы from и записывает их в таблицу to,
+// предварительно разбивая контент на фрагменты с помощью sp.
+// Если copyOnly равен true, записи копируются без разбивки.
+func Reindex(ctx context.Context, from string, to string, sp splitter.Chunker, copyOnly bool) error {
+	// БД из которой читаем записи
+	entries, err := OpenEntriesStorage(from)
+	if err != nil {
+		return fmt.Errorf("failed to initialize manticore client: %v", err)
+	}
+
+	// БД в которую пишем разделенные на фрагменты записи
+	splitEntries, err := OpenEntriesStorage(to)
+	if err != nil {
+		return fmt.Errorf("failed to initialize manticore client: %v", err)
+	}
+
+	ch, err := entries.FindAll(ctx, 10000)
+	if err != nil {
+		return fmt.Errorf("failed to find all entries: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			if copyOnly {
+				id, err := splitEntries.Storage.Insert(ctx, &e)
+				if err != nil {
+					return fmt.Errorf("failed to insert entry: %v", err)
+				}
+				log.Printf("Entry inserted: %v", *id)
+				continue
+			}
+
+			// Перекрытие с предыдущим фрагментом не должно попасть в новую разбивку
+			e.Content = e.OwnContent()
+			workerpool.Prepare(&e)
+			newEntries := sp.SplitEntry(ctx, e)
+
+			for _, newEntry := range newEntries {
+				_, err := splitEntries.Storage.Insert(ctx, &newEntry)
+				if err != nil {
+					return fmt.Errorf("failed to insert entry: %v", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/parser"
+	"github.com/terratensor/feed-parser/internal/watermark"
+	"github.com/terratensor/feed-parser/internal/websub"
+	"github.com/terratensor/feed-parser/internal/workerpool"
+)
+
+// RunService запускает парсеры всех лент из конфигурации и пул воркеров,
+// которые сохраняют полученные записи в мантикору. Работает до остановки процесса.
+func RunService(cfg *config.Config, m *metrics.Metrics) {
+	fp := gofeed.NewParser()
+	fp.UserAgent = cfg.UserAgent
+
+	ch := make(chan parser.Entry, cfg.EntryChanBuffer)
+
+	entriesStore := NewEntriesStorage(cfg.ManticoreIndex)
+	dedupIndex := NewDedupIndex(cfg, entriesStore)
+	entityTagger := NewTagger(cfg)
+	alertNotifier := NewAlertNotifier(cfg, m)
+
+	// Фильтр записей, не изменившихся с прошлого опроса лент
+	var wmFilter *watermark.Filter
+	if cfg.Incremental.Enabled {
+		store, err := watermark.NewFileStore(cfg.Incremental.Dir)
+		if err != nil {
+			log.Fatalf("failed to initialize watermark store: %v", err)
+		}
+		wmFilter = watermark.NewFilter(store, cfg.Incremental.FullScanEvery)
+	}
+
+	subscriber := NewWebSubSubscriber(cfg)
+	if subscriber != nil {
+		// Сохраняем ссылки на хабы в лентах Atom
+		fp.AtomTranslator = &websub.AtomTranslator{}
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, parserCfg := range cfg.Parsers {
+
+		wg.Add(1)
+		p := parser.NewParser(parserCfg, *cfg, m).WithChunker(NewChunker(cfg.SplitterFor(parserCfg))).
+			WithStorage(entriesStore).WithWatermark(wmFilter).WithWebSub(subscriber)
+
+		go p.Run(ch, fp, wg)
+	}
+
+	var allTask []*workerpool.Task
+
+	pool := workerpool.NewPool(allTask, cfg.Workers)
+
+	// События сохранения и обновления записей доставляются IndexNow, оповещениям и вебхукам
+	bus := NewEventBus(cfg, NewIndexNow(cfg), alertNotifier, NewTelegramPublisher(cfg, m))
+	if bus != nil {
+		bus.WithRecovery(EntryExists(entriesStore)).Start()
+	}
+
+	go func() {
+		for {
+			entry := <-ch
+			task := workerpool.NewTask(entry.Entry, entry.Chunker, entriesStore, cfg, m).WithDedup(dedupIndex).WithTagger(entityTagger).WithEvents(bus)
+			task.WithDone(func() { entry.Done(task.Err) })
+			pool.AddTask(task)
+		}
+	}()
+
+	pool.RunBackground()
+
+	wg.Wait()
+	log.Println("finished, all workers successfully stopped.")
+}
+
+// StartMetricsServer запускает http сервер с метриками prometheus на адресе addr
+func StartMetricsServer(addr string) {
+	http.Handle("/metrics", promhttp.Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}()
+}
+
+// NewWebSubSubscriber создает подписчика WebSub и запускает http сервер, который принимает
+// запросы хабов по адресу /websub/callback/. Если подписка выключена, возвращает nil.
+func NewWebSubSubscriber(cfg *config.Config) *websub.Subscriber {
+	if !cfg.WebSub.Enabled {
+		return nil
+	}
+
+	subscriber := websub.NewSubscriber(cfg.WebSub.CallbackURL, cfg.WebSub.Secret, cfg.WebSub.Lease, cfg.WebSub.RenewBefore)
+	mux := http.NewServeMux()
+	mux.Handle("/websub/callback/", subscriber)
+	go func() {
+		if err := http.ListenAndServe(cfg.WebSub.Listen, mux); err != nil {
+			log.Fatalf("Failed to start websub callback server: %v", err)
+		}
+	}()
+	log.Printf("websub callback server started at %v", cfg.WebSub.Listen)
+
+	return subscriber
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/splitter"
+)
+
+// NewChunker создает стратегию разбивки с размерами фрагментов и перекрытием из конфигурации
+func NewChunker(c config.Splitter) splitter.Chunker {
+	switch c.Strategy {
+	case splitter.StrategyHeading:
+		sp := splitter.NewHeadingSplitter(c.OptChunkSize, c.MaxChunkSize)
+		sp.WithOverlap(c.Overlap, c.OverlapUnit)
+		return sp
+	case splitter.StrategySpeaker:
+		sp := splitter.NewSpeakerSplitter(c.OptChunkSize, c.MaxChunkSize)
+		sp.WithOverlap(c.Overlap, c.OverlapUnit)
+		return sp
+	default:
+		return splitter.NewSplitter(c.OptChunkSize, c.MaxChunkSize).WithOverlap(c.Overlap, c.OverlapUnit)
+	}
+}
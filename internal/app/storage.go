@@ -8,15 +8,26 @@ import (
 )
 
 func NewEntriesStorage(index string) *feed.Entries {
+	entries, err := OpenEntriesStorage(index)
+	if err != nil {
+		log.Printf("failed to initialize manticore client, %v", err)
+		os.Exit(1)
+	}
+
+	return entries
+}
+
+// OpenEntriesStorage создает хранилище записей для индекса index,
+// в отличие от NewEntriesStorage возвращает ошибку, а не завершает процесс
+func OpenEntriesStorage(index string) (*feed.Entries, error) {
 	var storage feed.StorageInterface
 
 	manticoreClient, err := manticore.New(index)
 	if err != nil {
-		log.Printf("failed to initialize manticore client, %v", err)
-		os.Exit(1)
+		return nil, err
 	}
 
 	storage = manticoreClient
 
-	return feed.NewFeedStorage(storage)
+	return feed.NewFeedStorage(storage), nil
 }
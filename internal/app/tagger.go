@@ -0,0 +1,25 @@
+package app
+
+import (
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/tagger"
+)
+
+// NewTagger загружает словарь сущностей и создает Tagger.
+// Если отметка сущностей выключена или словарь не загружен, возвращает nil.
+func NewTagger(cfg *config.Config) *tagger.Tagger {
+	if !cfg.Tagger.Enabled {
+		return nil
+	}
+
+	dict, err := tagger.LoadDictionary(cfg.Tagger.Dictionary)
+	if err != nil {
+		log.Printf("failed to load tagger dictionary: %v", err)
+		return nil
+	}
+	log.Printf("tagger dictionary loaded, %d entities", len(dict.Entities))
+
+	return tagger.New(dict)
+}
@@ -0,0 +1,88 @@
+// Package attachment загружает документы PDF и DOCX, приложенные к записям,
+// и извлекает из них текст
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrTooLarge документ больше допустимого размера
+var ErrTooLarge = errors.New("attachment: file is too large")
+
+// ErrUnsupported формат документа не поддерживается
+var ErrUnsupported = errors.New("attachment: unsupported format")
+
+// Supported сообщает, можно ли извлечь текст из документа по его адресу или типу
+func Supported(url string, mimeType string) bool {
+	switch strings.ToLower(path.Ext(strings.SplitN(url, "?", 2)[0])) {
+	case ".pdf", ".docx":
+		return true
+	}
+	switch mimeType {
+	case "application/pdf", "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return true
+	}
+	return false
+}
+
+// Extract возвращает абзацы текста документа, формат определяется по содержимому.
+// Ошибка разбора поврежденного документа возвращается как ошибка и не завершает процесс.
+func Extract(data []byte) (paragraphs []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			paragraphs, err = nil, fmt.Errorf("attachment: failed to parse document: %v", r)
+		}
+	}()
+
+	switch {
+	case bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF")):
+		return pdfParagraphs(data)
+	case bytes.HasPrefix(data, []byte("PK")) && bytes.Contains(data, []byte("word/document.xml")):
+		return docxParagraphs(data)
+	}
+	return nil, ErrUnsupported
+}
+
+// Download загружает документ, если его размер не превышает maxSize байт
+func Download(ctx context.Context, url string, userAgent string, maxSize int64, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("attachment: %v: unexpected status %v", url, resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	// Размер может быть не указан в заголовках, поэтому читаем на байт больше лимита
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package attachment
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxParagraphs возвращает текст абзацев документа DOCX из word/document.xml
+func docxParagraphs(data []byte) ([]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("docx: %v", err)
+	}
+
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("docx: word/document.xml not found")
+	}
+
+	rc, err := doc.Open()
+	if err != nil {
+		return nil, fmt.Errorf("docx: %v", err)
+	}
+	defer rc.Close()
+
+	var paragraphs []string
+	var par strings.Builder
+	inText := false
+
+	dec := xml.NewDecoder(io.LimitReader(rc, maxStreamSize))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("docx: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				par.WriteString("\t")
+			case "br", "cr":
+				par.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if text := strings.TrimSpace(par.String()); text != "" {
+					paragraphs = append(paragraphs, text)
+				}
+				par.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				par.Write(t)
+			}
+		}
+	}
+
+	return paragraphs, nil
+}
@@ -0,0 +1,477 @@
+package attachment
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// objRe начало косвенного объекта "n g obj"
+var objRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+const (
+	// maxPDFSize максимальный размер разбираемого файла PDF
+	maxPDFSize = 64 << 20
+	// maxStreamSize максимальный размер распакованного потока
+	maxStreamSize = 32 << 20
+	// maxDecodedSize максимальный общий размер распакованных потоков документа
+	maxDecodedSize = 128 << 20
+	// maxObjects максимальное количество косвенных объектов документа, остальные объекты пропускаются
+	maxObjects = 1 << 18
+	// maxPageDepth максимальная глубина дерева страниц
+	maxPageDepth = 32
+	// maxRefChain максимальная длина цепочки ссылок на косвенные объекты
+	maxRefChain = 16
+)
+
+// errStreamTooLarge распакованный поток или все потоки документа больше допустимого размера
+var errStreamTooLarge = errors.New("pdf: decoded stream is too large")
+
+// pdfDoc объекты документа PDF по номерам
+type pdfDoc struct {
+	objects map[int]interface{}
+	trailer pdfDict
+	decoded int // Общий размер распакованных потоков
+}
+
+// pdfParagraphs возвращает текст абзацев документа PDF.
+// Поддерживаются потоки без сжатия и со сжатием FlateDecode, потоки объектов
+// и шрифты с таблицей ToUnicode или однобайтовой кодировкой.
+func pdfParagraphs(data []byte) ([]string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF")) {
+		return nil, fmt.Errorf("pdf: invalid header")
+	}
+	if len(data) > maxPDFSize {
+		return nil, ErrTooLarge
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, fmt.Errorf("pdf: encrypted documents are not supported")
+	}
+
+	doc := &pdfDoc{objects: make(map[int]interface{})}
+	doc.load(data)
+
+	var lines []string
+	for _, page := range doc.pages() {
+		lines = append(lines, doc.pageLines(page)...)
+	}
+
+	paragraphs := joinLines(lines)
+	if len(paragraphs) == 0 {
+		return nil, fmt.Errorf("pdf: no text found")
+	}
+	return paragraphs, nil
+}
+
+// load читает все косвенные объекты файла, включая объекты из потоков объектов
+func (d *pdfDoc) load(data []byte) {
+	for _, m := range objRe.FindAllSubmatchIndex(data, -1) {
+		num := atoi(data[m[2]:m[3]])
+		l := &pdfLexer{data: data, pos: m[1]}
+		obj, ok := l.object()
+		if !ok {
+			continue
+		}
+		if dict, isDict := obj.(pdfDict); isDict {
+			save := l.pos
+			if kw, ok := l.next(); ok && kw == pdfKeyword("stream") {
+				obj = pdfStream{dict: dict, data: streamData(data, l.pos)}
+			} else {
+				l.pos = save
+			}
+			if dictName(dict, "Type") == "XRef" && d.trailer == nil {
+				d.trailer = dict
+			}
+		}
+		// Более поздние объекты заменяют ранние при инкрементальном обновлении файла
+		d.setObject(num, obj)
+	}
+
+	// Трейлер классического файла
+	if i := bytes.LastIndex(data, []byte("trailer")); i >= 0 {
+		l := &pdfLexer{data: data, pos: i + len("trailer")}
+		if t, ok := l.object(); ok {
+			if dict, isDict := t.(pdfDict); isDict {
+				d.trailer = dict
+			}
+		}
+	}
+
+	// Объекты из потоков объектов
+	for _, obj := range d.objects {
+		s, ok := obj.(pdfStream)
+		if !ok || dictName(s.dict, "Type") != "ObjStm" {
+			continue
+		}
+		d.loadObjStm(s)
+	}
+}
+
+func (d *pdfDoc) loadObjStm(s pdfStream) {
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(s.dict["N"]).(int)
+	first, _ := d.resolve(s.dict["First"]).(int)
+	if first < 0 || first >= len(data) {
+		return
+	}
+
+	header := &pdfLexer{data: data}
+	for i := 0; i < n; i++ {
+		numTok, ok1 := header.next()
+		offTok, ok2 := header.next()
+		num, isNum := numTok.(int)
+		off, isOff := offTok.(int)
+		if !ok1 || !ok2 || !isNum || !isOff {
+			return
+		}
+		if _, exists := d.objects[num]; exists {
+			continue
+		}
+		pos := first + off
+		if off < 0 || pos < 0 || pos >= len(data) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: pos}
+		if obj, ok := l.object(); ok {
+			d.setObject(num, obj)
+		}
+	}
+}
+
+// setObject сохраняет объект num, новые объекты сверх maxObjects пропускаются
+func (d *pdfDoc) setObject(num int, obj interface{}) {
+	if _, exists := d.objects[num]; !exists && len(d.objects) >= maxObjects {
+		return
+	}
+	d.objects[num] = obj
+}
+
+// streamData возвращает данные потока, начинающиеся после ключевого слова stream
+func streamData(data []byte, pos int) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// resolve разыменовывает ссылку на косвенный объект
+func (d *pdfDoc) resolve(v interface{}) interface{} {
+	for i := 0; i < maxRefChain; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.objects[ref.num]
+	}
+	return nil
+}
+
+func (d *pdfDoc) dict(v interface{}) pdfDict {
+	switch t := d.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+// decode распаковывает данные потока, поддерживается только FlateDecode
+func (d *pdfDoc) decode(s pdfStream) ([]byte, error) {
+	var filters []pdfName
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = append(filters, f)
+	case pdfArray:
+		for _, v := range f {
+			if n, ok := d.resolve(v).(pdfName); ok {
+				filters = append(filters, n)
+			}
+		}
+	}
+
+	data := s.data
+	for _, f := range filters {
+		if f != "FlateDecode" && f != "Fl" {
+			return nil, fmt.Errorf("pdf: unsupported filter %v", f)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		// Читаем на байт больше лимита, чтобы сжатый в несколько байт поток не занял всю память
+		out, err := io.ReadAll(io.LimitReader(zr, maxStreamSize+1))
+		if len(out) > maxStreamSize || d.decoded+len(out) > maxDecodedSize {
+			return nil, errStreamTooLarge
+		}
+		// Поврежденный конец сжатого потока не мешает прочитать его начало
+		if err != nil && len(out) == 0 {
+			return nil, err
+		}
+		d.decoded += len(out)
+		data = out
+	}
+	return data, nil
+}
+
+// pdfPage страница и унаследованные ресурсы
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages возвращает страницы в порядке дерева страниц,
+// если дерево не найдено — все объекты /Page в порядке номеров
+func (d *pdfDoc) pages() []pdfPage {
+	var pages []pdfPage
+
+	// Узлы дерева могут ссылаться друг на друга по кругу, каждый узел обходим один раз
+	visited := make(map[uintptr]bool)
+	var walk func(node pdfDict, resources pdfDict, depth int)
+	walk = func(node pdfDict, resources pdfDict, depth int) {
+		if node == nil || depth > maxPageDepth {
+			return
+		}
+		ptr := reflect.ValueOf(node).Pointer()
+		if visited[ptr] {
+			return
+		}
+		visited[ptr] = true
+		if r := d.dict(node["Resources"]); r != nil {
+			resources = r
+		}
+		kids, _ := d.resolve(node["Kids"]).(pdfArray)
+		if dictName(node, "Type") == "Page" || kids == nil {
+			pages = append(pages, pdfPage{dict: node, resources: resources})
+			return
+		}
+		for _, kid := range kids {
+			walk(d.dict(kid), resources, depth+1)
+		}
+	}
+
+	if root := d.dict(d.trailer["Root"]); root != nil {
+		walk(d.dict(root["Pages"]), nil, 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	var nums []int
+	for num, obj := range d.objects {
+		if dict, ok := obj.(pdfDict); ok && dictName(dict, "Type") == "Page" {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		dict := d.objects[num].(pdfDict)
+		pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+	}
+	return pages
+}
+
+// pageLines возвращает строки текста страницы
+func (d *pdfDoc) pageLines(page pdfPage) []string {
+	var content []byte
+	switch c := d.resolve(page.dict["Contents"]).(type) {
+	case pdfStream:
+		content, _ = d.decode(c)
+	case pdfArray:
+		for _, part := range c {
+			if s, ok := d.resolve(part).(pdfStream); ok {
+				data, err := d.decode(s)
+				if err == nil {
+					content = append(content, data...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	fonts := make(map[pdfName]*pdfFont)
+	fontDicts := d.dict(page.resources["Font"])
+
+	var lines []string
+	var line strings.Builder
+	var font *pdfFont
+	lastY := math.NaN()
+
+	newLine := func() {
+		if text := strings.TrimSpace(line.String()); text != "" {
+			lines = append(lines, text)
+		}
+		line.Reset()
+	}
+	show := func(s pdfString) {
+		if font == nil {
+			font = defaultFont
+		}
+		line.WriteString(font.decode(s))
+	}
+
+	var operands []interface{}
+	l := &pdfLexer{data: content}
+	for {
+		obj, ok := l.object()
+		if !ok {
+			break
+		}
+		op, isOp := obj.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				name, _ := operands[len(operands)-2].(pdfName)
+				f, ok := fonts[name]
+				if !ok {
+					f = d.font(d.dict(fontDicts[name]))
+					fonts[name] = f
+				}
+				font = f
+			}
+		case "Tj":
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					show(s)
+				}
+			}
+		case "'", "\"":
+			newLine()
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					show(s)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].(pdfArray)
+				for _, v := range arr {
+					if s, ok := v.(pdfString); ok {
+						show(s)
+					} else if n, ok := toFloat(v); ok && n < -200 {
+						// Большой отступ между фрагментами означает пробел
+						line.WriteString(" ")
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := toFloat(operands[len(operands)-2])
+				ty, _ := toFloat(operands[len(operands)-1])
+				if ty != 0 {
+					newLine()
+				} else if tx > 0 {
+					line.WriteString(" ")
+				}
+			}
+		case "T*":
+			newLine()
+		case "Tm":
+			if len(operands) >= 6 {
+				y, _ := toFloat(operands[len(operands)-1])
+				if !math.IsNaN(lastY) && math.Abs(y-lastY) > 0.5 {
+					newLine()
+				} else if !math.IsNaN(lastY) {
+					line.WriteString(" ")
+				}
+				lastY = y
+			}
+		case "BT":
+			lastY = math.NaN()
+		case "ET":
+			newLine()
+		case "BI":
+			// Пропускаем встроенное изображение до EI
+			if i := bytes.Index(content[l.pos:], []byte("EI")); i >= 0 {
+				l.pos += i + 2
+			} else {
+				l.pos = len(content)
+			}
+		case "[", "]", "<<", ">>":
+			// Разделители вне массивов и словарей игнорируем
+			continue
+		}
+		operands = operands[:0]
+	}
+	newLine()
+
+	return lines
+}
+
+// joinLines объединяет строки страниц в абзацы: абзац заканчивается строкой,
+// которая завершается концом предложения. Перенос слова по дефису склеивается.
+func joinLines(lines []string) []string {
+	var paragraphs []string
+	var par strings.Builder
+
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if par.Len() > 0 {
+			prev := par.String()
+			if strings.HasSuffix(prev, "-") && startsLower(line) {
+				par.Reset()
+				par.WriteString(strings.TrimSuffix(prev, "-"))
+			} else {
+				par.WriteString(" ")
+			}
+		}
+		par.WriteString(line)
+
+		if endsParagraph(line) {
+			paragraphs = append(paragraphs, par.String())
+			par.Reset()
+		}
+	}
+	if par.Len() > 0 {
+		paragraphs = append(paragraphs, par.String())
+	}
+	return paragraphs
+}
+
+func endsParagraph(line string) bool {
+	r := []rune(line)
+	return len(r) > 0 && strings.ContainsRune(".!?:;»\"…", r[len(r)-1])
+}
+
+func startsLower(s string) bool {
+	for _, r := range s {
+		return r >= 'a' && r <= 'z' || r >= 'а' && r <= 'я' || r == 'ё'
+	}
+	return false
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,224 @@
+package attachment
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF собирает документ PDF из тел объектов, объект i получает номер i+1
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func stream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data []byte) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+	return b.Bytes()
+}
+
+var content = []byte("BT /F1 12 Tf 72 712 Td (Hello world.) Tj ET")
+
+func validPDF() []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		stream("/Filter /FlateDecode", deflate(content)),
+	)
+}
+
+func TestExtractPDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "flate content",
+			data: validPDF(),
+			want: "Hello world.",
+		},
+		{
+			name: "plain content",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				stream("", content),
+			),
+			want: "Hello world.",
+		},
+		{
+			name: "cyclic page tree",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [2 0 R 2 0 R 3 0 R 2 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				stream("", content),
+			),
+			want: "Hello world.",
+		},
+		{
+			name: "cyclic references",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"3 0 R",
+				"2 0 R",
+			),
+			wantErr: true,
+		},
+		{
+			name: "negative object stream offset",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				stream("/Type /ObjStm /N 1 /First 0", []byte("1 -50")),
+			),
+			wantErr: true,
+		},
+		{
+			name: "negative object stream first",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				stream("/Type /ObjStm /N 1 /First -100", []byte("1 0 (x)")),
+			),
+			wantErr: true,
+		},
+		{
+			name: "object stream offset past end",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				stream("/Type /ObjStm /N 1 /First 4", []byte("5 99999 ")),
+			),
+			wantErr: true,
+		},
+		{
+			name:    "deep nesting",
+			data:    buildPDF(strings.Repeat("[", 1<<20)),
+			wantErr: true,
+		},
+		{
+			name: "flate bomb",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				stream("/Filter /FlateDecode", deflate(make([]byte, maxStreamSize+1))),
+			),
+			wantErr: true,
+		},
+		{
+			name:    "encrypted",
+			data:    buildPDF("<< /Encrypt 2 0 R >>"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if strings.Join(got, "\n") != tt.want {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTruncatedPDF(t *testing.T) {
+	data := validPDF()
+	for n := 0; n < len(data); n++ {
+		// Ошибка допустима, паника — нет
+		Extract(data[:n])
+	}
+}
+
+func TestLexerOutOfRange(t *testing.T) {
+	for _, pos := range []int{-44, 100} {
+		l := &pdfLexer{data: []byte("1 0 R"), pos: pos}
+		if obj, ok := l.object(); ok {
+			t.Errorf("pos %d: object() = %v, want end of data", pos, obj)
+		}
+	}
+}
+
+func TestExtractPDFTooLarge(t *testing.T) {
+	data := append(validPDF(), make([]byte, maxPDFSize)...)
+	if _, err := Extract(data); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Extract() error = %v, want %v", err, ErrTooLarge)
+	}
+}
+
+func TestExtractRecoversPanic(t *testing.T) {
+	// Неверный архив DOCX не должен приводить к панике
+	if _, err := Extract([]byte("PK\x03\x04word/document.xml")); err == nil {
+		t.Error("Extract() error = nil, want error")
+	}
+}
+
+func FuzzExtract(f *testing.F) {
+	f.Add(validPDF())
+	f.Add(buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		stream("/Type /ObjStm /N 1 /First 0", []byte("1 -50")),
+	))
+	f.Add([]byte("%PDF-1.7\n1 0 obj << /Kids [1 0 R] >> endobj"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Extract(data)
+	})
+}
+
+// FuzzExtractPDF разбирает документы PDF без перехвата паники в Extract,
+// чтобы паника разбора PDF считалась ошибкой теста
+func FuzzExtractPDF(f *testing.F) {
+	f.Add(validPDF())
+	f.Add(buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [2 0 R 3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		stream("", content),
+		"<< /Type /Font /Subtype /Type1 /Encoding /WinAnsiEncoding >>",
+	))
+	f.Add(buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		stream("/Type /ObjStm /N 2 /First 8", []byte("3 0 4 30 << /Type /Pages /Kids [4 0 R] >> << /Type /Page >>")),
+	))
+	f.Add(buildPDF("<< /Type /Catalog /Pages 2 0 R >>", "3 0 R", "2 0 R"))
+	f.Add(buildPDF(strings.Repeat("<< /A [", 100)))
+	f.Add([]byte("%PDF-1.7\n1 0 obj << /Type /XRef /Root 1 0 R >> stream\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if !bytes.HasPrefix(data, []byte("%PDF")) {
+			data = append([]byte("%PDF-1.4\n"), data...)
+		}
+		paragraphs, err := pdfParagraphs(data)
+		if err == nil && len(paragraphs) == 0 {
+			t.Error("pdfParagraphs() returned no paragraphs without error")
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package attachment
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// pdfFont преобразует коды символов строки PDF в текст
+type pdfFont struct {
+	// codeLen длина кода символа в байтах, 2 для составных шрифтов
+	codeLen int
+	// toUnicode соответствие кодов символов тексту из таблицы ToUnicode
+	toUnicode map[int]string
+	// differences соответствие однобайтовых кодов символам из /Differences
+	differences map[int]rune
+}
+
+// defaultFont шрифт без таблиц, коды символов интерпретируются как Latin-1
+var defaultFont = &pdfFont{codeLen: 1}
+
+func (f *pdfFont) decode(s pdfString) string {
+	var sb strings.Builder
+	n := f.codeLen
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i+n <= len(s); i += n {
+		code := 0
+		for j := 0; j < n; j++ {
+			code = code<<8 | int(s[i+j])
+		}
+		if text, ok := f.toUnicode[code]; ok {
+			sb.WriteString(text)
+			continue
+		}
+		if r, ok := f.differences[code]; ok {
+			sb.WriteRune(r)
+			continue
+		}
+		if n == 1 && (code >= 0x20 || code == '\t') {
+			sb.WriteRune(rune(code))
+		}
+	}
+	return sb.String()
+}
+
+// font создает шрифт по словарю шрифта страницы
+func (d *pdfDoc) font(dict pdfDict) *pdfFont {
+	if dict == nil {
+		return defaultFont
+	}
+	f := &pdfFont{codeLen: 1}
+	if dictName(dict, "Subtype") == "Type0" {
+		f.codeLen = 2
+	}
+
+	if s, ok := d.resolve(dict["ToUnicode"]).(pdfStream); ok {
+		if data, err := d.decode(s); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+
+	if enc := d.dict(dict["Encoding"]); enc != nil {
+		if diff, ok := d.resolve(enc["Differences"]).(pdfArray); ok {
+			f.differences = parseDifferences(diff)
+		}
+	}
+
+	return f
+}
+
+// parseCMap читает соответствия bfchar и bfrange таблицы ToUnicode
+func parseCMap(data []byte) map[int]string {
+	m := make(map[int]string)
+	l := &pdfLexer{data: data}
+
+	for {
+		tok, ok := l.next()
+		if !ok {
+			break
+		}
+		switch tok {
+		case pdfKeyword("beginbfchar"):
+			for {
+				src, ok := l.object()
+				if !ok || src == pdfKeyword("endbfchar") {
+					break
+				}
+				dst, _ := l.object()
+				code, isCode := src.(pdfString)
+				text, isText := dst.(pdfString)
+				if isCode && isText {
+					m[codeValue(code)] = utf16Text(text)
+				}
+			}
+		case pdfKeyword("beginbfrange"):
+			for {
+				src, ok := l.object()
+				if !ok || src == pdfKeyword("endbfrange") {
+					break
+				}
+				hi, _ := l.object()
+				dst, _ := l.object()
+				lo, isLo := src.(pdfString)
+				up, isUp := hi.(pdfString)
+				if !isLo || !isUp {
+					continue
+				}
+				from, to := codeValue(lo), codeValue(up)
+				// Ограничиваем диапазон, чтобы поврежденная таблица не заняла всю память
+				if to < from || to-from > 0xffff {
+					continue
+				}
+				switch t := dst.(type) {
+				case pdfString:
+					base := []rune(utf16Text(t))
+					if len(base) == 0 {
+						continue
+					}
+					for code := from; code <= to; code++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(code - from)
+						m[code] = string(r)
+					}
+				case pdfArray:
+					for i, v := range t {
+						if s, ok := v.(pdfString); ok && from+i <= to {
+							m[from+i] = utf16Text(s)
+						}
+					}
+				}
+			}
+		}
+	}
+	return m
+}
+
+func codeValue(b pdfString) int {
+	code := 0
+	for _, c := range b {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+// utf16Text декодирует строку UTF-16BE из таблицы ToUnicode
+func utf16Text(b pdfString) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(b); i += 2 {
+		r := rune(b[i])<<8 | rune(b[i+1])
+		if r >= 0xd800 && r < 0xdc00 && i+3 < len(b) {
+			lo := rune(b[i+2])<<8 | rune(b[i+3])
+			if lo >= 0xdc00 && lo < 0xe000 {
+				r = (r-0xd800)<<10 + (lo - 0xdc00) + 0x10000
+				i += 2
+			}
+		}
+		if utf8.ValidRune(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// parseDifferences читает массив /Differences кодировки простого шрифта
+func parseDifferences(arr pdfArray) map[int]rune {
+	m := make(map[int]rune)
+	code := 0
+	for _, v := range arr {
+		switch t := v.(type) {
+		case int:
+			code = t
+		case pdfName:
+			if r, ok := glyphRune(string(t)); ok {
+				m[code] = r
+			}
+			code++
+		}
+	}
+	return m
+}
+
+// glyphNames имена глифов, которые чаще всего встречаются в /Differences
+var glyphNames = map[string]rune{
+	"space": ' ', "period": '.', "comma": ',', "colon": ':', "semicolon": ';',
+	"hyphen": '-', "endash": '–', "emdash": '—', "quotedbl": '"', "quotesingle": '\'',
+	"quoteleft": '‘', "quoteright": '’', "quotedblleft": '“', "quotedblright": '”',
+	"guillemotleft": '«', "guillemotright": '»', "exclam": '!', "question": '?',
+	"parenleft": '(', "parenright": ')', "slash": '/', "percent": '%', "numbersign": '№',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"fi": 'ﬁ', "fl": 'ﬂ', "bullet": '•', "ellipsis": '…',
+}
+
+// glyphRune возвращает символ по имени глифа: uniXXXX, однобуквенные имена
+// латиницы и распространенные знаки препинания
+func glyphRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if v, err := strconv.ParseUint(name[3:], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	return 0, false
+}
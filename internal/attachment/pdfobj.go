@@ -0,0 +1,309 @@
+package attachment
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Объекты PDF, которые нужны для извлечения текста
+
+type pdfName string
+
+type pdfString []byte
+
+type pdfKeyword string
+
+type pdfArray []interface{}
+
+type pdfDict map[pdfName]interface{}
+
+type pdfRef struct {
+	num int
+	gen int
+}
+
+// pdfStream словарь потока и его необработанные данные
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// maxNesting максимальная вложенность массивов и словарей
+const maxNesting = 64
+
+// pdfLexer читает объекты PDF из файла или потока содержимого страницы
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int // Вложенность читаемого массива или словаря
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	// Позиция вне данных означает конец данных
+	if l.pos < 0 || l.pos > len(l.data) {
+		l.pos = len(l.data)
+	}
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// next возвращает следующий токен: число, имя, строку, ключевое слово
+// или разделитель "[", "]", "<<", ">>" как pdfKeyword. В конце данных возвращает nil, false.
+func (l *pdfLexer) next() (interface{}, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodeName(l.data[start:l.pos])), true
+	case c == '(':
+		return l.literalString(), true
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfKeyword("<<"), true
+		}
+		return l.hexString(), true
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfKeyword(">>"), true
+		}
+		l.pos++
+		return pdfKeyword(">"), true
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(string(c)), true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		// Неизвестный символ пропускаем
+		l.pos++
+		return pdfKeyword(string(c)), true
+	}
+	word := string(l.data[start:l.pos])
+
+	if n, err := strconv.ParseInt(word, 10, 64); err == nil {
+		return int(n), true
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, true
+	}
+	switch word {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return pdfKeyword(word), true
+}
+
+func decodeName(b []byte) string {
+	if bytes.IndexByte(b, '#') < 0 {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	if l.pos < len(l.data) {
+		l.pos++ // >
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+// object читает объект PDF: словарь, массив, ссылку "n g R" или простой объект
+func (l *pdfLexer) object() (interface{}, bool) {
+	tok, ok := l.next()
+	if !ok {
+		return nil, false
+	}
+
+	switch t := tok.(type) {
+	case pdfKeyword:
+		if (t == "<<" || t == "[") && l.depth >= maxNesting {
+			return nil, false
+		}
+		switch t {
+		case "<<":
+			l.depth++
+			defer func() { l.depth-- }()
+			dict := pdfDict{}
+			for {
+				key, ok := l.object()
+				if !ok || key == pdfKeyword(">>") {
+					return dict, true
+				}
+				name, isName := key.(pdfName)
+				val, ok := l.object()
+				if !ok {
+					return dict, true
+				}
+				if isName {
+					dict[name] = val
+				}
+			}
+		case "[":
+			l.depth++
+			defer func() { l.depth-- }()
+			var arr pdfArray
+			for {
+				val, ok := l.object()
+				if !ok || val == pdfKeyword("]") {
+					return arr, true
+				}
+				arr = append(arr, val)
+			}
+		}
+		return t, true
+	case int:
+		// Проверяем, не является ли число началом ссылки "n g R"
+		save := l.pos
+		if gen, ok := l.next(); ok {
+			if g, isInt := gen.(int); isInt {
+				if r, ok := l.next(); ok && r == pdfKeyword("R") {
+					return pdfRef{num: t, gen: g}, true
+				}
+			}
+		}
+		l.pos = save
+		return t, true
+	}
+	return tok, true
+}
+
+func dictName(d pdfDict, key pdfName) pdfName {
+	n, _ := d[key].(pdfName)
+	return n
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
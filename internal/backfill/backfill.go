@@ -0,0 +1,278 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// ErrTooManyErrors возвращается, если подряд не удалось получить MaxErrors страниц
+var ErrTooManyErrors = errors.New("too many consecutive errors")
+
+// errEntriesFailed часть записей страницы не сохранена, страница обрабатывается повторно
+var errEntriesFailed = errors.New("page entries failed")
+
+// Page страница исторической ленты источника
+type Page struct {
+	Entries []feed.Entry
+	// Next адрес следующей (более старой) страницы, пустой, если страница последняя
+	Next string
+}
+
+// Entry запись страницы, переданная на обработку. После обработки записи вызывается Done,
+// позиция следующей страницы сохраняется, когда все записи страницы сохранены.
+type Entry struct {
+	feed.Entry
+	page *pageResult
+}
+
+// Done отмечает запись обработанной, err — ошибка, из-за которой запись не сохранена
+func (e Entry) Done(err error) {
+	e.page.done(err)
+}
+
+// pageResult результат обработки записей страницы
+type pageResult struct {
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	failed int
+	err    error
+}
+
+func (p *pageResult) done(err error) {
+	if err != nil {
+		p.mu.Lock()
+		p.failed++
+		p.err = err
+		p.mu.Unlock()
+	}
+	p.wg.Done()
+}
+
+// wait ожидает обработки записей страницы или отмены контекста
+// и возвращает ошибку, если часть записей не сохранена
+func (p *pageResult) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failed > 0 {
+		return fmt.Errorf("%w: %d entries are not stored: %v", errEntriesFailed, p.failed, p.err)
+	}
+	return nil
+}
+
+// Pager получает страницу ленты источника по ее адресу
+type Pager interface {
+	FetchPage(ctx context.Context, url string) (*Page, error)
+}
+
+// Options параметры обхода
+type Options struct {
+	// StartDate записи, опубликованные раньше этой даты, не индексируются,
+	// обход завершается на первой странице, все записи которой старше StartDate
+	StartDate *time.Time
+	// StopDate записи, опубликованные позже этой даты, пропускаются
+	StopDate *time.Time
+	// MaxPages максимальное количество страниц за один запуск, 0 — без ограничения
+	MaxPages int
+	// MaxErrors количество ошибок подряд, после которого обход прерывается
+	MaxErrors   int
+	Delay       time.Duration
+	RandomDelay time.Duration
+}
+
+// Runner обходит страницы ленты источника, начиная с сохраненной позиции,
+// и после обработки записей страницы фиксирует позицию следующей страницы
+type Runner struct {
+	Source   string
+	Key      string
+	StartUrl string
+	pager    Pager
+	store    CheckpointStore
+	opts     Options
+	metrics  *metrics.Metrics
+}
+
+func NewRunner(source string, startUrl string, pager Pager, store CheckpointStore, opts Options, metrics *metrics.Metrics) *Runner {
+	if opts.MaxErrors <= 0 {
+		opts.MaxErrors = 10
+	}
+	return &Runner{
+		Source:   source,
+		Key:      CheckpointKey(source, startUrl),
+		StartUrl: startUrl,
+		pager:    pager,
+		store:    store,
+		opts:     opts,
+		metrics:  metrics,
+	}
+}
+
+// CheckpointKey формирует ключ позиции из названия источника и начального адреса ленты
+func CheckpointKey(source string, startUrl string) string {
+	h := fnv.New32a()
+	h.Write([]byte(startUrl))
+	return fmt.Sprintf("%s-%08x", source, h.Sum32())
+}
+
+// Run выполняет обход до конца ленты или первой пустой страницы, до StartDate, до MaxPages страниц
+// или до отмены контекста. Позиция сохраняется после каждой страницы, когда для всех ее записей,
+// переданных в канал, вызван Entry.Done без ошибки. Если часть записей не сохранена, позиция не меняется,
+// страница запрашивается повторно, и это считается ошибкой наравне с ошибкой получения страницы.
+func (r *Runner) Run(ctx context.Context, ch chan<- Entry) error {
+	cp, err := r.store.Load(r.Key)
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		cp = &Checkpoint{Key: r.Key, Url: r.StartUrl, Page: 1}
+	}
+	if cp.Done {
+		log.Printf("backfill %v: already completed at page %d, nothing to do", r.Key, cp.Page)
+		return nil
+	}
+	if cp.Page > 1 {
+		log.Printf("🚩 backfill %v: resuming from page %d: %v", r.Key, cp.Page, cp.Url)
+	}
+
+	var errCount, pages int
+
+	for {
+		if r.opts.MaxPages > 0 && pages >= r.opts.MaxPages {
+			log.Printf("backfill %v: max pages %d reached, stopped at page %d", r.Key, r.opts.MaxPages, cp.Page)
+			return nil
+		}
+
+		if err := r.sleep(ctx); err != nil {
+			return err
+		}
+
+		page, err := r.pager.FetchPage(ctx, cp.Url)
+		if err != nil {
+			errCount++
+			r.metrics.BackfillErrors.WithLabelValues(r.Source, r.Key).Inc()
+			log.Printf("backfill %v: page %d error (%d/%d): %v", r.Key, cp.Page, errCount, r.opts.MaxErrors, err)
+			if errCount >= r.opts.MaxErrors {
+				return fmt.Errorf("backfill %v: page %d: %w: %v", r.Key, cp.Page, ErrTooManyErrors, err)
+			}
+			continue
+		}
+		pages++
+
+		entries, reachedStart := r.filter(page.Entries)
+		result := &pageResult{}
+		result.wg.Add(len(entries))
+		for _, entry := range entries {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- Entry{Entry: entry, page: result}:
+			}
+		}
+		if err := result.wait(ctx); err != nil {
+			if !errors.Is(err, errEntriesFailed) {
+				return err
+			}
+			errCount++
+			r.metrics.BackfillErrors.WithLabelValues(r.Source, r.Key).Inc()
+			log.Printf("backfill %v: page %d error (%d/%d): %v", r.Key, cp.Page, errCount, r.opts.MaxErrors, err)
+			if errCount >= r.opts.MaxErrors {
+				return fmt.Errorf("backfill %v: page %d: %w: %v", r.Key, cp.Page, ErrTooManyErrors, err)
+			}
+			continue
+		}
+		errCount = 0
+
+		log.Printf("✅ backfill %v: page %d, entries %d of %d", r.Key, cp.Page, len(entries), len(page.Entries))
+
+		r.metrics.BackfillPages.WithLabelValues(r.Source, r.Key).Inc()
+		r.metrics.BackfillEntries.WithLabelValues(r.Source, r.Key).Add(float64(len(entries)))
+		r.metrics.BackfillPage.WithLabelValues(r.Source, r.Key).Set(float64(cp.Page))
+
+		cp.Entries += len(entries)
+		cp.Updated = time.Now()
+		// Пустая страница означает, что лента пройдена до конца
+		done := page.Next == "" || reachedStart || len(page.Entries) == 0
+		if done {
+			cp.Done = true
+		} else {
+			cp.Url = page.Next
+			cp.Page++
+		}
+
+		if err := r.store.Save(cp); err != nil {
+			return fmt.Errorf("backfill %v: failed to save checkpoint: %v", r.Key, err)
+		}
+
+		if done {
+			log.Printf("🏁 backfill %v: completed at page %d, total entries %d", r.Key, cp.Page, cp.Entries)
+			return nil
+		}
+	}
+}
+
+// filter оставляет записи, опубликованные между StartDate и StopDate,
+// и сообщает, что на странице нет записей новее StartDate
+func (r *Runner) filter(entries []feed.Entry) ([]feed.Entry, bool) {
+	if r.opts.StartDate == nil && r.opts.StopDate == nil {
+		return entries, false
+	}
+
+	var result []feed.Entry
+	reachedStart := len(entries) > 0
+
+	for _, e := range entries {
+		if e.Published == nil || e.Published.IsZero() {
+			reachedStart = false
+			result = append(result, e)
+			continue
+		}
+		if r.opts.StartDate != nil && e.Published.Before(*r.opts.StartDate) {
+			continue
+		}
+		reachedStart = false
+		if r.opts.StopDate != nil && e.Published.After(*r.opts.StopDate) {
+			continue
+		}
+		result = append(result, e)
+	}
+
+	if r.opts.StartDate == nil {
+		reachedStart = false
+	}
+	return result, reachedStart
+}
+
+// sleep ожидает установленное время перед запросом следующей страницы
+func (r *Runner) sleep(ctx context.Context) error {
+	randomDelay := time.Duration(0)
+	if r.opts.RandomDelay != 0 {
+		randomDelay = time.Duration(rand.Int63n(int64(r.opts.RandomDelay)))
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(r.opts.Delay + randomDelay):
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// testPager лента из двух страниц по одной записи
+type testPager struct{}
+
+func (testPager) FetchPage(_ context.Context, url string) (*Page, error) {
+	if url == "page-2" {
+		return &Page{Entries: []feed.Entry{{Url: "entry-2"}}}, nil
+	}
+	return &Page{Entries: []feed.Entry{{Url: "entry-1"}}, Next: "page-2"}, nil
+}
+
+// consume обрабатывает записи из канала, store возвращает ошибку сохранения записи
+func consume(ch <-chan Entry, store func(feed.Entry) error) <-chan []string {
+	result := make(chan []string, 1)
+	go func() {
+		var urls []string
+		for e := range ch {
+			urls = append(urls, e.Url)
+			e.Done(store(e.Entry))
+		}
+		result <- urls
+	}()
+	return result
+}
+
+func run(t *testing.T, cps CheckpointStore, store func(feed.Entry) error) ([]string, error) {
+	t.Helper()
+	r := NewRunner("test", "page-1", testPager{}, cps, Options{MaxErrors: 3}, metrics.NewMetrics())
+	ch := make(chan Entry)
+	urls := consume(ch, store)
+	err := r.Run(context.Background(), ch)
+	close(ch)
+	return <-urls, err
+}
+
+func TestRunCompletes(t *testing.T) {
+	cps, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls, err := run(t, cps, func(feed.Entry) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 2 {
+		t.Errorf("processed %v, want entry-1 and entry-2", urls)
+	}
+
+	cp, err := cps.Load(CheckpointKey("test", "page-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp == nil || !cp.Done || cp.Page != 2 || cp.Entries != 2 {
+		t.Errorf("checkpoint = %+v, want done at page 2 with 2 entries", cp)
+	}
+}
+
+func TestRunKeepsCheckpointOnStoreFailure(t *testing.T) {
+	cps, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Первая страница сохраняется, вторая — нет
+	storeDown := func(e feed.Entry) error {
+		if e.Url == "entry-2" {
+			return errors.New("manticore is down")
+		}
+		return nil
+	}
+	urls, err := run(t, cps, storeDown)
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrTooManyErrors)
+	}
+	if len(urls) != 4 {
+		t.Errorf("processed %v, want entry-1 once and entry-2 for each of 3 attempts", urls)
+	}
+
+	key := CheckpointKey("test", "page-1")
+	cp, err := cps.Load(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp == nil || cp.Done || cp.Url != "page-2" || cp.Page != 2 || cp.Entries != 1 {
+		t.Fatalf("checkpoint = %+v, want page 2 not done", cp)
+	}
+
+	// После восстановления хранилища обход продолжается с несохраненной страницы
+	urls, err = run(t, cps, func(feed.Entry) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 || urls[0] != "entry-2" {
+		t.Errorf("resumed run processed %v, want entry-2", urls)
+	}
+	if cp, _ = cps.Load(key); cp == nil || !cp.Done {
+		t.Errorf("checkpoint = %+v, want done", cp)
+	}
+}
+
+func TestRunFirstPageFailure(t *testing.T) {
+	cps, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = run(t, cps, func(feed.Entry) error { return errors.New("manticore is down") })
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrTooManyErrors)
+	}
+	if cp, _ := cps.Load(CheckpointKey("test", "page-1")); cp != nil {
+		t.Errorf("checkpoint = %+v, want none", cp)
+	}
+}
+
+// listingPager список новостей, у страниц которого всегда есть следующая, после второй страницы пустой
+type listingPager struct {
+	fetched []string
+}
+
+func (p *listingPager) FetchPage(_ context.Context, url string) (*Page, error) {
+	p.fetched = append(p.fetched, url)
+	switch url {
+	case "page-1":
+		return &Page{Entries: []feed.Entry{{Url: "entry-1"}}, Next: "page-2"}, nil
+	case "page-2":
+		return &Page{Entries: []feed.Entry{{Url: "entry-2"}}, Next: "page-3"}, nil
+	}
+	return &Page{Next: url + "-next"}, nil
+}
+
+func TestRunStopsOnEmptyPage(t *testing.T) {
+	cps, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pager := &listingPager{}
+	r := NewRunner("test", "page-1", pager, cps, Options{MaxErrors: 3}, metrics.NewMetrics())
+	ch := make(chan Entry)
+	urls := consume(ch, func(feed.Entry) error { return nil })
+	err = r.Run(context.Background(), ch)
+	close(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-urls
+
+	if len(pager.fetched) != 3 {
+		t.Errorf("fetched %v, want to stop on the first empty page-3", pager.fetched)
+	}
+	cp, err := cps.Load(CheckpointKey("test", "page-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp == nil || !cp.Done || cp.Page != 3 || cp.Entries != 2 {
+		t.Errorf("checkpoint = %+v, want done at page 3 with 2 entries", cp)
+	}
+}
@@ -0,0 +1,60 @@
+package backfill
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+)
+
+// Checkpoint позиция исторической индексации источника.
+// Url — адрес страницы, с которой продолжится обход,
+// Page — номер этой страницы, считая с 1.
+type Checkpoint struct {
+	Key     string    `json:"key"`
+	Url     string    `json:"url"`
+	Page    int       `json:"page"`
+	Entries int       `json:"entries"`
+	Done    bool      `json:"done"`
+	Updated time.Time `json:"updated"`
+}
+
+// CheckpointStore хранилище позиций исторической индексации
+type CheckpointStore interface {
+	// Load возвращает сохраненную позицию по ключу или nil, если позиции нет
+	Load(key string) (*Checkpoint, error)
+	Save(cp *Checkpoint) error
+}
+
+// FileStore хранит позицию каждого источника в отдельном JSON файле в каталоге dir
+type FileStore struct {
+	dir string
+}
+
+var _ CheckpointStore = &FileStore{}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint dir %v: %v", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (fs *FileStore) Load(key string) (*Checkpoint, error) {
+	var cp Checkpoint
+	ok, err := jsonfile.Read(fs.filename(key), &cp)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (fs *FileStore) Save(cp *Checkpoint) error {
+	return jsonfile.Write(fs.filename(cp.Key), cp)
+}
+
+func (fs *FileStore) filename(key string) string {
+	return filepath.Join(fs.dir, key+".json")
+}
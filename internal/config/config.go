@@ -1,29 +1,296 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Env             string         `yaml:"env" env-default:"development"`
-	Workers         int            `yaml:"workers" env-default:"1"`
-	Delay           *time.Duration `yaml:"delay" env-default:"60s"`
-	RandomDelay     *time.Duration `yaml:"random_delay" env-default:"150s"`
-	UserAgent       string         `yaml:"user_agent" env-default:"Concepts/1.0"`
-	IndexNow        bool           `yaml:"index_now" env-default:"false"`
-	ManticoreIndex  string         `yaml:"manticore_index"`
-	EntryChanBuffer int            `yaml:"entry_chan_buffer" env-default:"20"`
-	Splitter        Splitter       `yaml:"splitter"`
-	Parsers         []Parser       `yaml:"parsers"`
+	Env             string          `yaml:"env" env-default:"development"`
+	Workers         int             `yaml:"workers" env-default:"1"`
+	Delay           *time.Duration  `yaml:"delay" env-default:"60s"`
+	RandomDelay     *time.Duration  `yaml:"random_delay" env-default:"150s"`
+	UserAgent       string          `yaml:"user_agent" env-default:"Concepts/1.0"`
+	IndexNow        bool            `yaml:"index_now" env-default:"false"`
+	IndexNowOptions IndexNowOptions `yaml:"index_now_options"`
+	ManticoreIndex  string          `yaml:"manticore_index"`
+	EntryChanBuffer int             `yaml:"entry_chan_buffer" env-default:"20"`
+	Splitter        Splitter        `yaml:"splitter"`
+	Backfill        Backfill        `yaml:"backfill"`
+	Incremental     Incremental     `yaml:"incremental"`
+	Attachments     Attachments     `yaml:"attachments"`
+	Language        Language        `yaml:"language"`
+	Linker          Linker          `yaml:"linker"`
+	Dedup           Dedup           `yaml:"dedup"`
+	Tagger          Tagger          `yaml:"tagger"`
+	Alerts          Alerts          `yaml:"alerts"`
+	Events          Events          `yaml:"events"`
+	WebSub          WebSub          `yaml:"websub"`
+	Telegram        Telegram        `yaml:"telegram"`
+	Parsers         []Parser        `yaml:"parsers"`
 }
 
 type Splitter struct {
-	OptChunkSize int `yaml:"opt_chunk_size" env-default:"1800"`
-	MaxChunkSize int `yaml:"max_chunk_size" env-default:"3600"`
+	OptChunkSize int    `yaml:"opt_chunk_size" env-default:"1800"`
+	MaxChunkSize int    `yaml:"max_chunk_size" env-default:"3600"`
+	Overlap      int    `yaml:"overlap" env-default:"0"`          // Размер перекрытия соседних фрагментов, 0 — без перекрытия
+	OverlapUnit  string `yaml:"overlap_unit" env-default:"chars"` // Единица размера перекрытия: chars или sentences
+	Strategy     string `yaml:"strategy" env-default:"size"`      // Стратегия разбивки: size, heading или speaker
+}
+
+// ParserSplitter параметры разбивки для отдельной ленты,
+// незаданные параметры берутся из раздела splitter
+type ParserSplitter struct {
+	Strategy     string `yaml:"strategy"`
+	OptChunkSize int    `yaml:"opt_chunk_size"`
+	MaxChunkSize int    `yaml:"max_chunk_size"`
+	Overlap      *int   `yaml:"overlap"`
+	OverlapUnit  string `yaml:"overlap_unit"`
+}
+
+// Attachments параметры загрузки документов PDF и DOCX, приложенных к записям
+type Attachments struct {
+	Enabled  bool          `yaml:"enabled" env-default:"false"`     // Извлекать текст вложений и индексировать его как фрагменты записи
+	MaxSize  int64         `yaml:"max_size" env-default:"10485760"` // Максимальный размер файла в байтах
+	MaxCount int           `yaml:"max_count" env-default:"3"`       // Максимальное количество вложений одной записи
+	Timeout  time.Duration `yaml:"timeout" env-default:"60s"`       // Время ожидания загрузки файла
+}
+
+// Language параметры определения языка записей
+type Language struct {
+	Detect        bool    `yaml:"detect" env-default:"false"`       // Определять язык по заголовку и контенту записи
+	MinConfidence float64 `yaml:"min_confidence" env-default:"0.1"` // Уверенность, начиная с которой язык считается определенным
+	Override      bool    `yaml:"override" env-default:"false"`     // Заменять язык ленты определенным языком записи
+}
+
+// Linker параметры объединения переводов одного материала в группы
+type Linker struct {
+	Enabled       bool          `yaml:"enabled" env-default:"false"`      // Присваивать записям идентификатор группы переводов
+	Window        time.Duration `yaml:"window" env-default:"2h"`          // Окно по времени публикации для поиска переводов без номера в адресе
+	MinSimilarity float64       `yaml:"min_similarity" env-default:"0.1"` // Минимальное сходство заголовков переводов от 0 до 1
+}
+
+// Dedup параметры поиска почти одинаковых записей разных источников
+type Dedup struct {
+	Enabled   bool          `yaml:"enabled" env-default:"false"` // Отмечать записи, повторяющие ранее полученные записи
+	Threshold int           `yaml:"threshold" env-default:"8"`   // Максимальное расстояние Хэмминга между отпечатками копий
+	Window    time.Duration `yaml:"window" env-default:"72h"`    // Максимальная разница дат публикации копий
+}
+
+// Tagger параметры отметки упоминаний стран, должностных лиц и организаций
+type Tagger struct {
+	Enabled    bool   `yaml:"enabled" env-default:"false"`                     // Отмечать упоминания сущностей словаря в записях
+	Dictionary string `yaml:"dictionary" env-default:"./config/entities.yaml"` // Путь к словарю сущностей
+}
+
+// IndexNowOptions параметры отправки адресов записей в поисковые системы по протоколу IndexNow
+type IndexNowOptions struct {
+	Endpoints    []string          `yaml:"endpoints" env-default:"https://yandex.com/indexnow"`                     // Адреса API IndexNow поисковых систем
+	KeyLocation  string            `yaml:"key_location"`                                                            // Адрес файла ключа, если он лежит не в корне сайта
+	URLTemplate  string            `yaml:"url_template" env-default:"https://feed.svodd.ru/{lang}/entry?url={url}"` // Шаблон публичного адреса записи
+	URLTemplates map[string]string `yaml:"url_templates"`                                                           // Шаблоны адреса записи для отдельных языков
+	Languages    []string          `yaml:"languages"`                                                               // Языки записей, адреса которых отправляются, пустой список — все языки
+	BatchWindow  time.Duration     `yaml:"batch_window" env-default:"10s"`                                          // Сколько накапливать адреса перед отправкой
+	BatchSize    int               `yaml:"batch_size" env-default:"10000"`                                          // Максимальное количество адресов в одном запросе
+	Retries      int               `yaml:"retries" env-default:"3"`                                                 // Количество повторных попыток при ответе 429 и 5xx
+	RetryDelay   time.Duration     `yaml:"retry_delay" env-default:"30s"`                                           // Задержка перед первой повторной попыткой, далее удваивается
+	Timeout      time.Duration     `yaml:"timeout" env-default:"10s"`                                               // Время ожидания ответа API
+	Queue        string            `yaml:"queue" env-default:"./data/indexnow/queue.json"`                          // Файл очереди адресов, ожидающих отправки
+}
+
+// defaultURLTemplates шаблоны адреса записи по умолчанию, русские записи публикуются без префикса языка
+var defaultURLTemplates = map[string]string{
+	"ru": "https://feed.svodd.ru/entry?url={url}",
+}
+
+// EntryURL возвращает публичный адрес записи url на языке lang по шаблону из url_templates или url_template.
+// В шаблоне {lang} заменяется на язык, {url} — на адрес записи в кодировке для параметра запроса.
+func (o IndexNowOptions) EntryURL(lang string, entryURL string) string {
+	tmpl, ok := o.URLTemplates[lang]
+	if !ok && o.URLTemplates == nil {
+		tmpl, ok = defaultURLTemplates[lang]
+	}
+	if !ok {
+		tmpl = o.URLTemplate
+	}
+	return strings.NewReplacer("{lang}", lang, "{url}", url.QueryEscape(entryURL)).Replace(tmpl)
+}
+
+// Submits сообщает, отправляются ли в IndexNow записи на языке lang
+func (o IndexNowOptions) Submits(lang string) bool {
+	if len(o.Languages) == 0 {
+		return true
+	}
+	for _, l := range o.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Alerts параметры оповещений о новых записях, подходящих под сохраненные запросы
+type Alerts struct {
+	Enabled    bool          `yaml:"enabled" env-default:"false"`     // Проверять новые и обновленные записи по сохраненным запросам
+	Table      string        `yaml:"table" env-default:"feed_alerts"` // Таблица мантикоры типа pq с сохраненными запросами
+	Retries    int           `yaml:"retries" env-default:"3"`         // Количество повторных попыток доставки оповещения
+	RetryDelay time.Duration `yaml:"retry_delay" env-default:"10s"`   // Задержка перед первой повторной попыткой, далее удваивается
+	Timeout    time.Duration `yaml:"timeout" env-default:"10s"`       // Время ожидания ответа вебхука
+	QueueSize  int           `yaml:"queue_size" env-default:"100"`    // Размер очереди оповещений, ожидающих доставки
+	Webhooks   []Webhook     `yaml:"webhooks"`
+}
+
+// Events параметры журнала событий изменения записей
+type Events struct {
+	Enabled     bool          `yaml:"enabled" env-default:"false"`     // Сохранять события в журнал outbox перед доставкой подписчикам
+	Dir         string        `yaml:"dir" env-default:"./data/outbox"` // Каталог журнала событий и позиций подписчиков
+	Retention   time.Duration `yaml:"retention" env-default:"72h"`     // Сколько хранить сегменты журнала, прочитанные всеми подписчиками
+	MaxAttempts int           `yaml:"max_attempts" env-default:"10"`   // Количество попыток доставки события подписчику, 0 — без ограничения
+	Timeout     time.Duration `yaml:"timeout" env-default:"10s"`       // Время ожидания ответа вебхука
+	Webhooks    []Webhook     `yaml:"webhooks"`                        // Вебхуки, на которые отправляются все события
+}
+
+// WebSub параметры подписки на обновления лент через хабы WebSub
+type WebSub struct {
+	Enabled     bool          `yaml:"enabled" env-default:"false"`    // Подписываться на ленты, в которых объявлен хаб
+	Listen      string        `yaml:"listen" env-default:":8090"`     // Адрес, на котором служба принимает запросы хабов
+	CallbackURL string        `yaml:"callback_url"`                   // Публичный адрес обработчика /websub/callback, доступный хабам
+	Secret      string        `yaml:"secret" env:"WEBSUB_SECRET"`     // Ключ для секретов подписок, которыми хабы подписывают содержимое, обязателен
+	Lease       time.Duration `yaml:"lease" env-default:"240h"`       // Запрашиваемый срок подписки
+	RenewBefore time.Duration `yaml:"renew_before" env-default:"24h"` // За сколько до окончания подписка продлевается
+}
+
+// Telegram параметры публикации новых записей в чаты и каналы через Telegram Bot API
+type Telegram struct {
+	Enabled       bool           `yaml:"enabled" env-default:"false"`                     // Публиковать новые записи
+	BaseURL       string         `yaml:"base_url" env-default:"https://api.telegram.org"` // Адрес Bot API
+	Token         string         `yaml:"token" env:"TELEGRAM_BOT_TOKEN"`                  // Токен бота
+	Chats         []TelegramChat `yaml:"chats"`                                           // Чаты и каналы, в которые публикуются записи
+	Interval      time.Duration  `yaml:"interval" env-default:"3s"`                       // Минимальный интервал между сообщениями в один чат
+	MaxAge        time.Duration  `yaml:"max_age" env-default:"24h"`                       // Записи, опубликованные раньше, не отправляются, 0 — без ограничения
+	SummaryLength int            `yaml:"summary_length" env-default:"600"`                // Максимальная длина анонса записи в символах
+	Timeout       time.Duration  `yaml:"timeout" env-default:"10s"`                       // Время ожидания ответа Bot API
+	Log           string         `yaml:"log" env-default:"./data/telegram/sent.json"`     // Журнал отправленных сообщений
+	Retention     time.Duration  `yaml:"retention" env-default:"720h"`                    // Сколько хранить отметки об отправке в журнале
+}
+
+// TelegramChat чат или канал Telegram и записи, которые в него публикуются. Пустой список означает любое значение.
+type TelegramChat struct {
+	ChatID         string   `yaml:"chat_id"`         // Идентификатор чата или имя канала @channel
+	Resources      []int    `yaml:"resources"`       // Идентификаторы ресурсов записей
+	Languages      []string `yaml:"languages"`       // Языки записей
+	DisablePreview bool     `yaml:"disable_preview"` // Не показывать предпросмотр ссылки
+}
+
+// Match сообщает, что запись ресурса resourceID на языке lang публикуется в чат
+func (c TelegramChat) Match(resourceID int, lang string) bool {
+	if len(c.Resources) > 0 {
+		found := false
+		for _, id := range c.Resources {
+			found = found || id == resourceID
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.Languages) > 0 {
+		found := false
+		for _, l := range c.Languages {
+			found = found || l == lang
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate проверяет параметры публикации в Telegram
+func (t Telegram) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	var errs []error
+	if t.Token == "" {
+		errs = append(errs, errors.New("token is not set"))
+	}
+	if _, err := url.ParseRequestURI(t.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid base_url %q: %v", t.BaseURL, err))
+	}
+	if len(t.Chats) == 0 {
+		errs = append(errs, errors.New("chats are not set"))
+	}
+	ids := make(map[string]bool)
+	for n, c := range t.Chats {
+		if c.ChatID == "" {
+			errs = append(errs, fmt.Errorf("chats[%d]: chat_id is not set", n))
+		} else if ids[c.ChatID] {
+			errs = append(errs, fmt.Errorf("chats[%d]: duplicate chat_id %q", n, c.ChatID))
+		}
+		ids[c.ChatID] = true
+	}
+	if t.Retention <= 0 {
+		errs = append(errs, fmt.Errorf("retention must be positive, got %v", t.Retention))
+	}
+	if t.SummaryLength < 0 {
+		errs = append(errs, fmt.Errorf("summary_length must not be negative, got %d", t.SummaryLength))
+	}
+	return errors.Join(errs...)
+}
+
+// Webhook адрес доставки оповещений или событий
+type Webhook struct {
+	Name   string `yaml:"name"`   // Имя вебхука, указывается в сохраненном запросе или определяет позицию в журнале событий
+	Url    string `yaml:"url"`    // Адрес, на который отправляется POST запрос с оповещением в формате JSON
+	Secret string `yaml:"secret"` // Ключ подписи HMAC-SHA256 тела запроса, заголовок X-Feed-Signature
+}
+
+// Incremental параметры инкрементального опроса лент
+type Incremental struct {
+	Enabled       bool   `yaml:"enabled" env-default:"false"`        // Пропускать записи, не изменившиеся с прошлого опроса
+	Dir           string `yaml:"dir" env-default:"./data/watermark"` // Каталог для сохранения состояния опроса лент
+	FullScanEvery int    `yaml:"full_scan_every" env-default:"24"`   // Каждый N-й цикл опроса обрабатывать все записи ленты, 0 — никогда
+}
+
+// Backfill параметры исторической индексации
+type Backfill struct {
+	CheckpointDir string `yaml:"checkpoint_dir" env-default:"./data/backfill"` // Каталог для сохранения позиций обхода
+	StartDate     string `yaml:"start_date"`                                   // Самая ранняя дата публикации записей, формат 2006-01-02
+	StopDate      string `yaml:"stop_date"`                                    // Самая поздняя дата публикации записей, формат 2006-01-02
+	MaxPages      int    `yaml:"max_pages" env-default:"0"`                    // Максимальное количество страниц за запуск, 0 — без ограничения
+	MaxErrors     int    `yaml:"max_errors" env-default:"10"`                  // Количество ошибок подряд, после которого обход прерывается
+}
+
+// Dates возвращает границы дат публикации, nil если граница не задана.
+// Дата окончания включает весь указанный день.
+func (b Backfill) Dates() (start *time.Time, stop *time.Time, err error) {
+	if b.StartDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, b.StartDate, time.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("backfill: invalid start_date: %v", err)
+		}
+		start = &t
+	}
+	if b.StopDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, b.StopDate, time.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("backfill: invalid stop_date: %v", err)
+		}
+		t = t.Add(24*time.Hour - time.Second)
+		stop = &t
+	}
+	if start != nil && stop != nil && stop.Before(*start) {
+		return nil, nil, errors.New("backfill: stop_date is before start_date")
+	}
+	return start, stop, nil
 }
 
 type Parser struct {
@@ -34,6 +301,64 @@ type Parser struct {
 	Delay       *time.Duration `yaml:"delay,omitempty"`
 	RandomDelay *time.Duration `yaml:"random_delay,omitempty"`
 	Crawler     Crawler        `yaml:"crawler"` // Конфигурация для краулера
+	Pagination  Pagination     `yaml:"pagination"`
+	Splitter    ParserSplitter `yaml:"splitter"`   // Параметры разбивки для ленты
+	IDPattern   string         `yaml:"id_pattern"` // Регулярное выражение номера материала в адресе записи для связывания переводов
+}
+
+// SplitterFor возвращает параметры разбивки для ленты p с учетом переопределений ленты
+func (c *Config) SplitterFor(p Parser) Splitter {
+	s := c.Splitter
+	if p.Splitter.Strategy != "" {
+		s.Strategy = p.Splitter.Strategy
+	}
+	if p.Splitter.OptChunkSize > 0 {
+		s.OptChunkSize = p.Splitter.OptChunkSize
+	}
+	if p.Splitter.MaxChunkSize > 0 {
+		s.MaxChunkSize = p.Splitter.MaxChunkSize
+	}
+	if p.Splitter.Overlap != nil {
+		s.Overlap = *p.Splitter.Overlap
+	}
+	if p.Splitter.OverlapUnit != "" {
+		s.OverlapUnit = p.Splitter.OverlapUnit
+	}
+	return s
+}
+
+// Validate проверяет параметры разбивки
+func (s Splitter) Validate() error {
+	var errs []error
+	if s.OptChunkSize > s.MaxChunkSize {
+		errs = append(errs, fmt.Errorf("opt_chunk_size %d is greater than max_chunk_size %d", s.OptChunkSize, s.MaxChunkSize))
+	}
+	if s.Overlap < 0 {
+		errs = append(errs, fmt.Errorf("overlap must not be negative, got %d", s.Overlap))
+	}
+	if s.OverlapUnit != "chars" && s.OverlapUnit != "sentences" {
+		errs = append(errs, fmt.Errorf("overlap_unit must be chars or sentences, got %q", s.OverlapUnit))
+	}
+	switch s.Strategy {
+	case "size", "heading", "speaker":
+	default:
+		errs = append(errs, fmt.Errorf("strategy must be size, heading or speaker, got %q", s.Strategy))
+	}
+	return errors.Join(errs...)
+}
+
+// Pagination параметры постраничного чтения API источника (сейчас JSON API mil.ru)
+type Pagination struct {
+	PageParam   string         `yaml:"page_param"`   // Имя параметра номера страницы, например page
+	OffsetParam string         `yaml:"offset_param"` // Имя параметра смещения, например offset
+	PageSize    int            `yaml:"page_size"`    // Размер страницы для расчета смещения, если 0, то количество полученных записей
+	MaxPages    int            `yaml:"max_pages"`    // Максимальное количество страниц при периодическом опросе
+	MaxAge      *time.Duration `yaml:"max_age"`      // При периодическом опросе не переходить на следующую страницу, если записи старше
+}
+
+// Enabled сообщает, задан ли хотя бы один параметр пагинации
+func (p Pagination) Enabled() bool {
+	return p.PageParam != "" || p.OffsetParam != ""
 }
 
 type Crawler struct {
@@ -53,9 +378,19 @@ func MustLoad() *Config {
 		log.Fatal("CONFIG_PATH environment variable is not set")
 	}
 
+	cfg, err := Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cfg
+}
+
+// Load читает конфиг-файл по указанному пути и возвращает заполненную структуру Config
+func Load(configPath string) (*Config, error) {
 	// Проверяем существование конфиг-файла
 	if _, err := os.Stat(configPath); err != nil {
-		log.Fatalf("error opening config file: %s", err)
+		return nil, fmt.Errorf("error opening config file: %s", err)
 	}
 
 	var cfg Config
@@ -63,8 +398,151 @@ func MustLoad() *Config {
 	// Читаем конфиг-файл и заполняем нашу структуру
 	err := cleanenv.ReadConfig(configPath, &cfg)
 	if err != nil {
-		log.Fatalf("error reading config file: %s", err)
+		return nil, fmt.Errorf("error reading config file: %s", err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate проверяет конфигурацию на наличие очевидных ошибок:
+// пустой список парсеров, парсеры без url, языка или resource_id,
+// некорректные границы фрагментов и задержек краулера.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.ManticoreIndex == "" {
+		errs = append(errs, errors.New("manticore_index is not set"))
+	}
+	if c.Workers < 1 {
+		errs = append(errs, fmt.Errorf("workers must be positive, got %d", c.Workers))
+	}
+	if err := c.Splitter.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("splitter: %w", err))
+	}
+	if c.Attachments.Enabled && c.Attachments.MaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("attachments: max_size must be positive, got %d", c.Attachments.MaxSize))
+	}
+	if c.Language.MinConfidence < 0 || c.Language.MinConfidence > 1 {
+		errs = append(errs, fmt.Errorf("language: min_confidence must be between 0 and 1, got %v", c.Language.MinConfidence))
+	}
+	if c.Dedup.Threshold < 0 || c.Dedup.Threshold > 64 {
+		errs = append(errs, fmt.Errorf("dedup: threshold must be between 0 and 64, got %d", c.Dedup.Threshold))
+	}
+	if c.Tagger.Enabled {
+		if _, err := os.Stat(c.Tagger.Dictionary); err != nil {
+			errs = append(errs, fmt.Errorf("tagger: dictionary: %v", err))
+		}
+	}
+	if err := c.Alerts.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("alerts: %w", err))
+	}
+	if err := c.Telegram.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("telegram: %w", err))
+	}
+	if c.IndexNow {
+		for n, e := range c.IndexNowOptions.Endpoints {
+			if _, err := url.ParseRequestURI(e); err != nil {
+				errs = append(errs, fmt.Errorf("index_now_options: endpoints[%d]: invalid url %q: %v", n, e, err))
+			}
+		}
+		if c.IndexNowOptions.BatchSize < 1 || c.IndexNowOptions.BatchSize > 10000 {
+			errs = append(errs, fmt.Errorf("index_now_options: batch_size must be between 1 and 10000, got %d", c.IndexNowOptions.BatchSize))
+		}
+	}
+	if c.WebSub.Enabled {
+		if _, err := url.ParseRequestURI(c.WebSub.CallbackURL); err != nil {
+			errs = append(errs, fmt.Errorf("websub: invalid callback_url %q: %v", c.WebSub.CallbackURL, err))
+		}
+		// Без секрета подпись содержимого не проверить, и любой может прислать записи на callback_url
+		if c.WebSub.Secret == "" {
+			errs = append(errs, errors.New("websub: secret is not set"))
+		}
+	}
+	if c.Events.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("events: max_attempts must not be negative, got %d", c.Events.MaxAttempts))
 	}
+	eventWebhooks := make(map[string]bool)
+	for n, w := range c.Events.Webhooks {
+		if w.Name == "" {
+			errs = append(errs, fmt.Errorf("events: webhooks[%d]: name is not set", n))
+		} else if eventWebhooks[w.Name] {
+			errs = append(errs, fmt.Errorf("events: webhooks[%d]: duplicate name %q", n, w.Name))
+		}
+		eventWebhooks[w.Name] = true
+		if _, err := url.ParseRequestURI(w.Url); err != nil {
+			errs = append(errs, fmt.Errorf("events: webhooks[%d]: invalid url %q: %v", n, w.Url, err))
+		}
+	}
+	if _, _, err := c.Backfill.Dates(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(c.Parsers) == 0 {
+		errs = append(errs, errors.New("parsers list is empty"))
+	}
+
+	for n, p := range c.Parsers {
+		if p.Url == "" {
+			errs = append(errs, fmt.Errorf("parsers[%d]: url is not set", n))
+		} else if _, err := url.ParseRequestURI(p.Url); err != nil {
+			errs = append(errs, fmt.Errorf("parsers[%d]: invalid url %q: %v", n, p.Url, err))
+		}
+		if p.Lang == "" {
+			errs = append(errs, fmt.Errorf("parsers[%d]: lang is not set", n))
+		}
+		if p.ResourceID == 0 {
+			errs = append(errs, fmt.Errorf("parsers[%d]: resource_id is not set", n))
+		}
+		if p.Crawler.RandomDelayMax < p.Crawler.RandomDelayMin {
+			errs = append(errs, fmt.Errorf("parsers[%d]: crawler random_delay_max is less than random_delay_min", n))
+		}
+		if p.Crawler.SleepMax < p.Crawler.SleepMin {
+			errs = append(errs, fmt.Errorf("parsers[%d]: crawler sleep_max is less than sleep_min", n))
+		}
+		if p.IDPattern != "" {
+			if _, err := regexp.Compile(p.IDPattern); err != nil {
+				errs = append(errs, fmt.Errorf("parsers[%d]: invalid id_pattern: %v", n, err))
+			}
+		}
+		if p.Splitter != (ParserSplitter{}) {
+			if err := c.SplitterFor(p).Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("parsers[%d]: splitter: %w", n, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
 
-	return &cfg
+// Validate проверяет уникальность имен и адреса вебхуков оповещений
+func (a Alerts) Validate() error {
+	var errs []error
+	if a.Enabled && a.Table == "" {
+		errs = append(errs, errors.New("table is not set"))
+	}
+	if a.Retries < 0 {
+		errs = append(errs, fmt.Errorf("retries must not be negative, got %d", a.Retries))
+	}
+	names := make(map[string]bool)
+	for n, w := range a.Webhooks {
+		if w.Name == "" {
+			errs = append(errs, fmt.Errorf("webhooks[%d]: name is not set", n))
+		} else if names[w.Name] {
+			errs = append(errs, fmt.Errorf("webhooks[%d]: duplicate name %q", n, w.Name))
+		}
+		names[w.Name] = true
+		if _, err := url.ParseRequestURI(w.Url); err != nil {
+			errs = append(errs, fmt.Errorf("webhooks[%d]: invalid url %q: %v", n, w.Url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Webhook возвращает вебхук по имени
+func (a Alerts) Webhook(name string) (Webhook, bool) {
+	for _, w := range a.Webhooks {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Webhook{}, false
 }
@@ -7,9 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/terratensor/feed-parser/internal/config"
 	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/media"
 	"github.com/terratensor/feed-parser/internal/metrics"
 )
 
@@ -53,21 +55,13 @@ func VisitMil(entry *feed.Entry, config *config.Crawler, metrics *metrics.Metric
 		title := e.ChildText("h1")
 
 		// Фильтруем ненужные данные
-		content := e.ChildTexts("p")
+		content := paragraphsHTML(e, "p")
 
 		author := e.ChildText("div a.date")
 
-		var sb strings.Builder
-		for _, con := range content {
-			if len(con) > 0 {
-				sb.WriteString("<p>")
-				sb.WriteString(con)
-				sb.WriteString("</p>")
-			}
-		}
-
 		entry.Title = title
-		entry.Content = sb.String()
+		entry.Content = content
+		entry.Media = feed.MergeMedia(entry.Media, elementMedia(e, entry.Url))
 		entry.Author = author
 
 		log.Printf("Crawling Title: %v", entry.Title)
@@ -121,19 +115,11 @@ func VisitMid(entry *feed.Entry, config *config.Crawler, metrics *metrics.Metric
 		number := e.ChildText("p.article-line__note.article-line__note_small")
 
 		// filter out unwanted data
-		content := e.ChildTexts("div.text.article-content p")
-
-		var sb strings.Builder
-		for _, con := range content {
-			if len(con) > 0 {
-				sb.WriteString("<p>")
-				sb.WriteString(con)
-				sb.WriteString("</p>")
-			}
-		}
+		content := paragraphsHTML(e, "div.text.article-content p")
 
 		entry.Title = title
-		entry.Content = sb.String()
+		entry.Content = content
+		entry.Media = feed.MergeMedia(entry.Media, elementMedia(e, entry.Url))
 		entry.Number = number
 
 		log.Printf("Mid photo-content: %v", entry.Title)
@@ -148,19 +134,11 @@ func VisitMid(entry *feed.Entry, config *config.Crawler, metrics *metrics.Metric
 		number := e.ChildText("div.announcement__doc-num")
 
 		// filter out unwanted data
-		content := e.ChildTexts("div.announcement__text > p")
-
-		var sb strings.Builder
-		for _, con := range content {
-			if len(con) > 0 {
-				sb.WriteString("<p>")
-				sb.WriteString(con)
-				sb.WriteString("</p>")
-			}
-		}
+		content := paragraphsHTML(e, "div.announcement__text > p")
 
 		entry.Title = title
-		entry.Content = sb.String()
+		entry.Content = content
+		entry.Media = feed.MergeMedia(entry.Media, elementMedia(e, entry.Url))
 		entry.Number = number
 
 		log.Printf("Mid announcements: %v", entry.Title)
@@ -207,3 +185,31 @@ func VisitMid(entry *feed.Entry, config *config.Crawler, metrics *metrics.Metric
 
 	return entry, nil
 }
+
+// paragraphsHTML возвращает разметку абзацев selector с сохранением ссылок и выделения,
+// окончательная очистка разметки выполняется пакетом sanitize
+func paragraphsHTML(e *colly.HTMLElement, selector string) string {
+	var sb strings.Builder
+	e.DOM.Find(selector).Each(func(_ int, s *goquery.Selection) {
+		if strings.TrimSpace(s.Text()) == "" {
+			return
+		}
+		inner, err := s.Html()
+		if err != nil {
+			return
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(inner)
+		sb.WriteString("</p>")
+	})
+	return sb.String()
+}
+
+// elementMedia возвращает изображения, видео и вложения элемента страницы
+func elementMedia(e *colly.HTMLElement, base string) []feed.Media {
+	inner, err := e.DOM.Html()
+	if err != nil {
+		return nil
+	}
+	return media.Extract(inner, base)
+}
@@ -0,0 +1,126 @@
+// Package dedup находит записи, которые почти полностью повторяют ранее полученные записи
+// других источников, по отпечаткам SimHash
+package dedup
+
+import (
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/simhash"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+)
+
+// maxItems максимальное количество отпечатков в индексе
+const maxItems = 50000
+
+type item struct {
+	fingerprint uint64
+	url         string
+	resourceID  int
+	groupID     int64
+	published   time.Time
+}
+
+// Index индекс отпечатков недавних записей, безопасен для использования из нескольких горутин
+type Index struct {
+	mu        sync.Mutex
+	items     []item
+	threshold int
+	window    time.Duration
+}
+
+// NewIndex создает индекс, записи считаются копиями, если расстояние Хэмминга между
+// отпечатками не больше threshold, а даты публикации отличаются не больше чем на window
+func NewIndex(threshold int, window time.Duration) *Index {
+	return &Index{
+		threshold: threshold,
+		window:    window,
+	}
+}
+
+// Fingerprint вычисляет отпечаток заголовка и контента записи
+func Fingerprint(e feed.Entry) int64 {
+	text := e.Title + "\n" + html.UnescapeString(striphtml.StripHtmlTags(e.OwnContent()))
+	if strings.TrimSpace(text) == "" {
+		return 0
+	}
+	return int64(simhash.Fingerprint(text))
+}
+
+// Seed добавляет в индекс ранее сохраненные записи, которые не являются копиями
+func (ix *Index) Seed(entries []feed.Entry) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, e := range entries {
+		if e.Fingerprint == 0 || e.DuplicateOf != "" {
+			continue
+		}
+		ix.add(e)
+	}
+}
+
+// Match ищет в индексе запись, копией которой является e, и возвращает ее адрес.
+// Если такой записи нет, то e добавляется в индекс и возвращается пустая строка.
+// Записи того же ресурса и переводы одного материала из одной группы копиями не считаются.
+func (ix *Index) Match(e feed.Entry) string {
+	if e.Fingerprint == 0 {
+		return ""
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var published time.Time
+	if e.Published != nil {
+		published = *e.Published
+	}
+
+	best, bestDistance := "", ix.threshold+1
+	for _, it := range ix.items {
+		if it.url == e.Url || it.resourceID == e.ResourceID || (e.GroupID != 0 && it.groupID == e.GroupID) {
+			continue
+		}
+		if !published.IsZero() && !it.published.IsZero() && absDuration(published.Sub(it.published)) > ix.window {
+			continue
+		}
+		if d := simhash.Distance(uint64(it.fingerprint), uint64(e.Fingerprint)); d < bestDistance {
+			best, bestDistance = it.url, d
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	ix.add(e)
+	return ""
+}
+
+func (ix *Index) add(e feed.Entry) {
+	for n, it := range ix.items {
+		if it.url == e.Url {
+			ix.items = append(ix.items[:n], ix.items[n+1:]...)
+			break
+		}
+	}
+
+	it := item{fingerprint: uint64(e.Fingerprint), url: e.Url, resourceID: e.ResourceID, groupID: e.GroupID}
+	if e.Published != nil {
+		it.published = *e.Published
+	}
+	ix.items = append(ix.items, it)
+
+	if len(ix.items) > maxItems {
+		ix.items = ix.items[len(ix.items)-maxItems:]
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
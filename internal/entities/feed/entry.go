@@ -10,20 +10,41 @@ import (
 )
 
 type Entry struct {
-	ID         *int64     `json:"id"`
-	Language   string     `json:"language"`
-	Title      string     `json:"title"`
-	Url        string     `json:"url"`
-	Updated    *time.Time `json:"updated"`
-	Published  *time.Time `json:"published"`
-	Created    *time.Time `json:"created"`
-	UpdatedAt  *time.Time `json:"updated_at"`
-	Summary    string     `json:"summary"`
-	Content    string     `json:"content"`
-	Author     string     `json:"author"`
-	Number     string     `json:"number"`
-	ResourceID int        `json:"resource_id"`
-	Chunk      int        `json:"chunk"`
+	ID                 *int64     `json:"id"`
+	Language           string     `json:"language"`
+	Title              string     `json:"title"`
+	Url                string     `json:"url"`
+	Updated            *time.Time `json:"updated"`
+	Published          *time.Time `json:"published"`
+	Created            *time.Time `json:"created"`
+	UpdatedAt          *time.Time `json:"updated_at"`
+	Summary            string     `json:"summary"`
+	Content            string     `json:"content"`
+	Author             string     `json:"author"`
+	Number             string     `json:"number"`
+	ResourceID         int        `json:"resource_id"`
+	Chunk              int        `json:"chunk"`
+	ChunkTotal         int        `json:"chunk_total"`         // Количество фрагментов записи
+	ChunkOffset        int        `json:"chunk_offset"`        // Смещение начала фрагмента в символах в тексте записи без разметки
+	Overlap            int        `json:"overlap"`             // Длина в байтах перекрытия с предыдущим фрагментом в начале Content
+	Section            string     `json:"section"`             // Заголовок раздела или первое предложение фрагмента
+	Speaker            string     `json:"speaker"`             // Говорящий реплики стенограммы, к которой относится фрагмент
+	Media              []Media    `json:"media"`               // Изображения, видео и вложения записи
+	Attachment         string     `json:"attachment"`          // Адрес вложения, из текста которого получен фрагмент
+	DetectedLanguage   string     `json:"detected_language"`   // Язык, определенный по заголовку и контенту записи
+	LanguageConfidence float64    `json:"language_confidence"` // Уверенность определения языка от 0 до 1
+	GroupID            int64      `json:"group_id"`            // Группа переводов одного материала на разные языки
+	Fingerprint        int64      `json:"fingerprint"`         // Отпечаток SimHash заголовка и контента записи
+	DuplicateOf        string     `json:"duplicate_of"`        // Адрес записи, почти полной копией которой является запись
+	Tags               []int64    `json:"tags"`                // Идентификаторы стран, лиц и организаций, упомянутых в записи
+}
+
+// OwnContent возвращает контент фрагмента без перекрытия с предыдущим фрагментом
+func (e Entry) OwnContent() string {
+	if e.Overlap <= 0 || e.Overlap > len(e.Content) {
+		return e.Content
+	}
+	return e.Content[e.Overlap:]
 }
 
 type StorageInterface interface {
@@ -36,6 +57,9 @@ type StorageInterface interface {
 	FindDuration(ctx context.Context, duration time.Duration) (chan string, error)
 	CalculateLimitCount(duration time.Duration) int
 	Delete(ctx context.Context, id *int64) error
+	FindByGroup(ctx context.Context, groupID int64) ([]Entry, error)
+	FindPublished(ctx context.Context, resourceID int, from time.Time, to time.Time) ([]Entry, error)
+	FindByTags(ctx context.Context, tags []int64, query string, limit int) ([]Entry, error)
 }
 
 type Entries struct {
@@ -48,6 +72,24 @@ func NewFeedStorage(store StorageInterface) *Entries {
 	}
 }
 
+// Translations возвращает переводы записи e на другие языки из той же группы переводов
+func (es *Entries) Translations(ctx context.Context, e Entry) ([]Entry, error) {
+	if e.GroupID == 0 {
+		return nil, nil
+	}
+	group, err := es.Storage.FindByGroup(ctx, e.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	var translations []Entry
+	for _, t := range group {
+		if t.Url != e.Url && t.Language != e.Language {
+			translations = append(translations, t)
+		}
+	}
+	return translations, nil
+}
+
 func (es *Entries) FindAll(ctx context.Context, limit int) (chan Entry, error) {
 
 	chin, err := es.Storage.FindAll(ctx, limit)
@@ -100,22 +142,30 @@ func (es *Entries) Find(ctx context.Context, duration time.Duration) (chan Entry
 				var builder strings.Builder
 
 				for _, chunk := range chunks {
-					builder.WriteString(chunk.Content)
+					// Текст вложений не входит в контент записи
+					if chunk.Attachment != "" {
+						continue
+					}
+					builder.WriteString(chunk.OwnContent())
 				}
 
 				entry := Entry{
-					Language:   chunks[0].Language,
-					Title:      chunks[0].Title,
-					Url:        chunks[0].Url,
-					Updated:    chunks[0].Updated,
-					Published:  chunks[0].Published,
-					Created:    chunks[0].Created,
-					UpdatedAt:  chunks[0].UpdatedAt,
-					Summary:    chunks[0].Summary,
-					Content:    builder.String(),
-					Author:     chunks[0].Author,
-					Number:     chunks[0].Number,
-					ResourceID: chunks[0].ResourceID,
+					Language:    chunks[0].Language,
+					Title:       chunks[0].Title,
+					Url:         chunks[0].Url,
+					Updated:     chunks[0].Updated,
+					Published:   chunks[0].Published,
+					Created:     chunks[0].Created,
+					UpdatedAt:   chunks[0].UpdatedAt,
+					Summary:     chunks[0].Summary,
+					Content:     builder.String(),
+					Author:      chunks[0].Author,
+					Number:      chunks[0].Number,
+					ResourceID:  chunks[0].ResourceID,
+					Media:       chunks[0].Media,
+					GroupID:     chunks[0].GroupID,
+					DuplicateOf: chunks[0].DuplicateOf,
+					Tags:        chunks[0].Tags,
 				}
 
 				chout <- entry
@@ -141,6 +191,7 @@ func MakeEntries(items []*gofeed.Item, url link.Link) []Entry {
 			Summary:    item.Description,
 			Content:    content,
 			ResourceID: url.ResourceID,
+			Media:      mediaFromItem(item),
 		}
 
 		entries = append(entries, *e)
@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Типы медиа
+const (
+	MediaImage    = "image"
+	MediaVideo    = "video"
+	MediaAudio    = "audio"
+	MediaDocument = "document"
+)
+
+// Media изображение, видео или вложение записи
+type Media struct {
+	Url      string `json:"url"`
+	Type     string `json:"type"`                // image, video, audio или document
+	MimeType string `json:"mime_type,omitempty"` // MIME-тип, если известен
+	Caption  string `json:"caption,omitempty"`   // Подпись или название
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+}
+
+// MergeMedia объединяет списки медиа без повторов адресов, сохраняя порядок
+func MergeMedia(lists ...[]Media) []Media {
+	var result []Media
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, m := range list {
+			if m.Url == "" || seen[m.Url] {
+				continue
+			}
+			seen[m.Url] = true
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// mediaFromItem возвращает медиа элемента ленты: изображение, вложения enclosure и media:content
+func mediaFromItem(item *gofeed.Item) []Media {
+	var media []Media
+
+	if item.Image != nil && item.Image.URL != "" {
+		media = append(media, Media{Url: item.Image.URL, Type: MediaImage, Caption: item.Image.Title})
+	}
+
+	for _, enc := range item.Enclosures {
+		media = append(media, Media{Url: enc.URL, Type: MediaType(enc.Type, ""), MimeType: enc.Type})
+	}
+
+	for _, ext := range item.Extensions["media"]["content"] {
+		attrs := ext.Attrs
+		m := Media{
+			Url:      attrs["url"],
+			Type:     MediaType(attrs["type"], attrs["medium"]),
+			MimeType: attrs["type"],
+		}
+		m.Width, _ = strconv.Atoi(attrs["width"])
+		m.Height, _ = strconv.Atoi(attrs["height"])
+		for _, d := range ext.Children["description"] {
+			m.Caption = d.Value
+		}
+		media = append(media, m)
+	}
+
+	return MergeMedia(media)
+}
+
+// MediaType определяет тип медиа по атрибуту medium или MIME-типу
+func MediaType(mimeType string, medium string) string {
+	switch medium {
+	case MediaImage, MediaVideo, MediaAudio, MediaDocument:
+		return medium
+	}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return MediaImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return MediaVideo
+	case strings.HasPrefix(mimeType, "audio/"):
+		return MediaAudio
+	}
+	return MediaDocument
+}
@@ -0,0 +1,195 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSize количество событий, читаемых из журнала за раз
+	batchSize = 100
+	// pollInterval интервал проверки журнала на события других процессов
+	pollInterval = 5 * time.Second
+	// compactInterval интервал удаления прочитанных сегментов журнала
+	compactInterval = time.Hour
+	// maxRetryDelay максимальная задержка между повторными попытками доставки
+	maxRetryDelay = 5 * time.Minute
+)
+
+type subscriber struct {
+	name    string
+	handler Handler
+	wake    chan struct{}
+}
+
+// Bus шина событий. Если задан журнал outbox, события сохраняются в журнал, а каждый подписчик
+// читает журнал со своей позиции независимо от других и получает каждое событие хотя бы один раз.
+// Без журнала события передаются подписчикам сразу при публикации, ошибки подписчиков только логируются.
+type Bus struct {
+	outbox      *Outbox
+	maxAttempts int
+	subs        []*subscriber
+	exists      Exists // Проверка наличия записи для восстановления потерянных событий
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewBus создает шину событий, outbox nil — без журнала. Событие, которое подписчик
+// не смог обработать за maxAttempts попыток, пропускается, 0 — попытки не ограничены.
+func NewBus(outbox *Outbox, maxAttempts int) *Bus {
+	return &Bus{
+		outbox:      outbox,
+		maxAttempts: maxAttempts,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Subscribe добавляет подписчика name, вызывается до Start. Имя определяет файл позиции
+// подписчика в журнале и не должно меняться между запусками.
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.subs = append(b.subs, &subscriber{name: name, handler: h, wake: make(chan struct{}, 1)})
+}
+
+// Start запускает доставку событий из журнала подписчикам, восстановление потерянных событий
+// и удаление прочитанных сегментов журнала
+func (b *Bus) Start() {
+	if b.outbox == nil {
+		return
+	}
+	for _, s := range b.subs {
+		b.wg.Add(1)
+		go b.dispatch(s)
+	}
+	b.wg.Add(1)
+	go b.maintain()
+}
+
+// Close останавливает доставку, предварительно доставив подписчикам события, уже записанные в журнал
+func (b *Bus) Close() {
+	close(b.stop)
+	b.wg.Wait()
+}
+
+// Publish публикует событие
+func (b *Bus) Publish(ctx context.Context, ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	if b.outbox == nil {
+		for _, s := range b.subs {
+			if err := s.handler(ctx, ev); err != nil {
+				log.Printf("events: subscriber %v failed to handle %v %v: %v", s.name, ev.Type, ev.Entry.Url, err)
+			}
+		}
+		return nil
+	}
+
+	if err := b.outbox.Append(ev); err != nil {
+		return err
+	}
+	for _, s := range b.subs {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// dispatch читает журнал с позиции подписчика и передает ему события по порядку
+func (b *Bus) dispatch(s *subscriber) {
+	defer b.wg.Done()
+
+	c, err := b.outbox.loadCursor(s.name)
+	if err != nil {
+		log.Printf("events: failed to load cursor of %v: %v", s.name, err)
+		return
+	}
+
+	for {
+		records, next, err := b.outbox.read(c, batchSize)
+		if err != nil {
+			log.Printf("events: failed to read outbox for %v: %v", s.name, err)
+		}
+
+		for _, rec := range records {
+			if !b.deliver(s, rec.event) {
+				return
+			}
+			c = rec.next
+			if err := b.outbox.saveCursor(s.name, c); err != nil {
+				log.Printf("events: failed to save cursor of %v: %v", s.name, err)
+			}
+		}
+		if next != c {
+			c = next
+			if err := b.outbox.saveCursor(s.name, c); err != nil {
+				log.Printf("events: failed to save cursor of %v: %v", s.name, err)
+			}
+		}
+		if len(records) > 0 {
+			continue
+		}
+
+		select {
+		case <-b.stop:
+			return
+		case <-s.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// deliver передает событие подписчику, повторяя попытки с удваивающейся задержкой.
+// Возвращает false, если шина остановлена до успешной доставки.
+func (b *Bus) deliver(s *subscriber, ev Event) bool {
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		err := s.handler(context.Background(), ev)
+		if err == nil {
+			return true
+		}
+		if b.maxAttempts > 0 && attempt >= b.maxAttempts {
+			log.Printf("events: subscriber %v gave up on event %v %v after %d attempts: %v", s.name, ev.ID, ev.Type, attempt, err)
+			return true
+		}
+		log.Printf("events: subscriber %v failed to handle event %v %v, attempt %d: %v", s.name, ev.ID, ev.Type, attempt, err)
+
+		select {
+		case <-b.stop:
+			return false
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// maintain при запуске и затем периодически публикует потерянные события
+// и удаляет сегменты журнала, прочитанные всеми подписчиками
+func (b *Bus) maintain() {
+	defer b.wg.Done()
+
+	names := make([]string, 0, len(b.subs))
+	for _, s := range b.subs {
+		names = append(names, s.name)
+	}
+
+	for {
+		if err := b.recover(context.Background()); err != nil {
+			log.Printf("events: failed to recover pending events: %v", err)
+		}
+		if err := b.outbox.Compact(names); err != nil {
+			log.Printf("events: failed to compact outbox: %v", err)
+		}
+		select {
+		case <-b.stop:
+			return
+		case <-time.After(compactInterval):
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package events публикует события сохранения, обновления и удаления записей
+// и доставляет их подписчикам: IndexNow, оповещениям, вебхукам
+package events
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// Type тип события
+type Type string
+
+const (
+	EntryCreated Type = "entry.created"
+	EntryUpdated Type = "entry.updated"
+	EntryDeleted Type = "entry.deleted"
+)
+
+// Event событие изменения записи
+type Event struct {
+	ID    string     `json:"id,omitempty"`   // Позиция события в журнале outbox, заполняется при доставке
+	Type  Type       `json:"type"`           // Тип события
+	Time  time.Time  `json:"time"`           // Время публикации события
+	Entry feed.Entry `json:"entry"`          // Запись целиком, для EntryDeleted — последняя сохраненная версия
+	Diff  []Change   `json:"diff,omitempty"` // Изменившиеся поля записи, только для EntryUpdated
+}
+
+// Change изменение поля записи
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Handler обработчик событий подписчика. Если обработчик возвращает ошибку,
+// событие доставляется повторно.
+type Handler func(ctx context.Context, ev Event) error
+
+// Diff возвращает поля, которые отличаются у прежней и новой версии записи
+func Diff(old, new feed.Entry) []Change {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"title", old.Title, new.Title},
+		{"summary", old.Summary, new.Summary},
+		{"content", old.Content, new.Content},
+		{"author", old.Author, new.Author},
+		{"language", old.Language, new.Language},
+		{"published", formatTime(old.Published), formatTime(new.Published)},
+		{"updated", formatTime(old.Updated), formatTime(new.Updated)},
+		{"media", len(old.Media), len(new.Media)},
+		{"group_id", old.GroupID, new.GroupID},
+		{"duplicate_of", old.DuplicateOf, new.DuplicateOf},
+		{"tags", sortedTags(old.Tags), sortedTags(new.Tags)},
+	}
+
+	var changes []Change
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.old, f.new) {
+			changes = append(changes, Change{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+// FromChunks собирает запись из сохраненных фрагментов: контент фрагментов записи без перекрытий
+// склеивается по порядку, фрагменты вложений не учитываются, остальные поля берутся из первого фрагмента
+func FromChunks(chunks []feed.Entry) feed.Entry {
+	if len(chunks) == 0 {
+		return feed.Entry{}
+	}
+
+	sorted := append([]feed.Entry(nil), chunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chunk < sorted[j].Chunk })
+
+	e := sorted[0]
+	var content strings.Builder
+	for _, c := range sorted {
+		if c.Attachment != "" {
+			continue
+		}
+		content.WriteString(c.OwnContent())
+	}
+	e.ID = nil
+	e.Content = content.String()
+	e.Chunk, e.ChunkTotal, e.ChunkOffset, e.Overlap = 0, 0, 0, 0
+	e.Section, e.Speaker = "", ""
+	return e
+}
+
+// ChunksHash возвращает отпечаток фрагментов записи с 1 по chunk_total первого фрагмента.
+// Отпечаток фрагментов, подготовленных к сохранению, совпадает с отпечатком тех же фрагментов,
+// прочитанных из хранилища, только если сохранены все фрагменты.
+func ChunksHash(chunks []feed.Entry) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+
+	sorted := append([]feed.Entry(nil), chunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chunk < sorted[j].Chunk })
+
+	h := sha1.New()
+	total := sorted[0].ChunkTotal
+	fmt.Fprintf(h, "%d\n", total)
+	for _, c := range sorted {
+		if c.Chunk > total {
+			break
+		}
+		fmt.Fprintf(h, "%d\n%s\n%s\n%s\n", c.Chunk, c.Title, c.Attachment, c.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sortedTags(tags []int64) []int64 {
+	if len(tags) == 0 {
+		return nil
+	}
+	s := append([]int64(nil), tags...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s
+}
@@ -0,0 +1,256 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+)
+
+const (
+	segmentPrefix = "events-"
+	segmentSuffix = ".log"
+	// segmentGrace время после окончания суток, в течение которого в сегмент еще могут
+	// дописываться события процессов, начавших запись до полуночи
+	segmentGrace = time.Minute
+)
+
+// Outbox журнал событий в каталоге dir. События записываются строками JSON в сегменты
+// events-YYYYMMDD.log по дате публикации, позиция каждого подписчика хранится в файле <name>.cursor.
+// Дописывать события в журнал могут несколько процессов, читать — только служба.
+type Outbox struct {
+	dir       string
+	retention time.Duration
+}
+
+// cursor позиция подписчика в журнале: сегмент и смещение следующего события в байтах
+type cursor struct {
+	Segment string `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// record событие журнала и позиция следующего за ним события
+type record struct {
+	event Event
+	next  cursor
+}
+
+// OpenOutbox открывает журнал событий в каталоге dir. Сегменты, прочитанные всеми подписчиками,
+// удаляются через retention после окончания суток сегмента.
+func OpenOutbox(dir string, retention time.Duration) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox dir %v: %v", dir, err)
+	}
+	return &Outbox{dir: dir, retention: retention}, nil
+}
+
+// Append дописывает событие в сегмент текущих суток и сбрасывает его на диск
+func (o *Outbox) Append(ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	data = append(data, '\n')
+
+	name := filepath.Join(o.dir, segmentName(ev.Time))
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox segment: %v", err)
+	}
+	defer f.Close()
+
+	// Строка, оборванная при падении процесса, завершается переводом строки,
+	// чтобы новое событие не склеилось с ней
+	torn, err := tornTail(f)
+	if err != nil {
+		return fmt.Errorf("failed to check outbox segment: %v", err)
+	}
+	if torn {
+		data = append([]byte{'\n'}, data...)
+	}
+
+	// Строка записывается одним вызовом, чтобы строки разных процессов не перемешивались
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to append event: %v", err)
+	}
+	return f.Sync()
+}
+
+// read возвращает не больше limit событий, следующих за позицией c, и позицию после прочитанных событий.
+// Позиция может перейти в начало следующего сегмента и без событий.
+func (o *Outbox) read(c cursor, limit int) ([]record, cursor, error) {
+	segments, err := o.segments()
+	if err != nil || len(segments) == 0 {
+		return nil, c, err
+	}
+	if c.Segment == "" || c.Segment < segments[0] {
+		c = cursor{Segment: segments[0]}
+	}
+
+	var records []record
+	for len(records) < limit {
+		rs, next, end, err := o.readSegment(c, limit-len(records))
+		records = append(records, rs...)
+		c = next
+		if err != nil || !end {
+			return records, c, err
+		}
+
+		// Сегмент прочитан до конца, переходим к следующему, когда в текущий больше не пишут
+		next = cursor{Segment: nextSegment(segments, c.Segment)}
+		if next.Segment == "" || !segmentClosed(c.Segment) {
+			break
+		}
+		c = next
+	}
+	return records, c, nil
+}
+
+// readSegment читает полные строки сегмента начиная с позиции c, возвращает позицию после прочитанных строк
+// и сообщает, достигнут ли конец сегмента
+func (o *Outbox) readSegment(c cursor, limit int) ([]record, cursor, bool, error) {
+	f, err := os.Open(filepath.Join(o.dir, c.Segment))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, c, true, nil
+	}
+	if err != nil {
+		return nil, c, false, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(c.Offset, io.SeekStart); err != nil {
+		return nil, c, false, err
+	}
+
+	var records []record
+	r := bufio.NewReader(f)
+	for len(records) < limit {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// Неполная строка — событие еще дописывается
+			return records, c, true, nil
+		}
+		if err != nil {
+			return records, c, false, err
+		}
+
+		rec := record{next: cursor{Segment: c.Segment, Offset: c.Offset + int64(len(line))}}
+		if err := json.Unmarshal(line, &rec.event); err != nil {
+			// Строка, оборванная при падении процесса, пропускается, иначе чтение журнала остановится на ней
+			log.Printf("events: skipping broken event in %v at %d: %v", c.Segment, c.Offset, err)
+			c = rec.next
+			continue
+		}
+		rec.event.ID = fmt.Sprintf("%s:%d", strings.TrimSuffix(c.Segment, segmentSuffix), c.Offset)
+		records = append(records, rec)
+		c = rec.next
+	}
+	return records, c, false, nil
+}
+
+// tornTail сообщает, что сегмент f не заканчивается переводом строки
+func tornTail(f *os.File) (bool, error) {
+	stat, err := f.Stat()
+	if err != nil || stat.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, stat.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// Compact удаляет сегменты, которые прочитаны всеми подписчиками names и старше retention
+func (o *Outbox) Compact(names []string) error {
+	segments, err := o.segments()
+	if err != nil || len(segments) == 0 {
+		return err
+	}
+
+	oldest := segments[len(segments)-1]
+	for _, name := range names {
+		c, err := o.loadCursor(name)
+		if err != nil {
+			return err
+		}
+		if c.Segment < oldest {
+			oldest = c.Segment
+		}
+	}
+
+	for _, s := range segments {
+		if s >= oldest {
+			break
+		}
+		day, err := segmentDay(s)
+		if err != nil || time.Since(day.Add(24*time.Hour)) < o.retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(o.dir, s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Outbox) loadCursor(name string) (cursor, error) {
+	var c cursor
+	_, err := jsonfile.Read(filepath.Join(o.dir, name+".cursor"), &c)
+	return c, err
+}
+
+func (o *Outbox) saveCursor(name string, c cursor) error {
+	return jsonfile.Write(filepath.Join(o.dir, name+".cursor"), c)
+}
+
+// segments возвращает имена сегментов журнала по возрастанию даты
+func (o *Outbox) segments() ([]string, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), segmentPrefix) && strings.HasSuffix(e.Name(), segmentSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func segmentName(t time.Time) string {
+	return segmentPrefix + t.UTC().Format("20060102") + segmentSuffix
+}
+
+func segmentDay(name string) (time.Time, error) {
+	return time.Parse("20060102", strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix))
+}
+
+// segmentClosed сообщает, что сутки сегмента закончились и в него больше не пишут
+func segmentClosed(name string) bool {
+	day, err := segmentDay(name)
+	if err != nil {
+		return true
+	}
+	return time.Since(day.Add(24*time.Hour)) > segmentGrace
+}
+
+func nextSegment(segments []string, current string) string {
+	for _, s := range segments {
+		if s > current {
+			return s
+		}
+	}
+	return ""
+}
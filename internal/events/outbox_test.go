@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+func TestOutboxTornLine(t *testing.T) {
+	o, err := OpenOutbox(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := o.Append(Event{Type: EntryCreated, Time: now, Entry: feed.Entry{Url: "first"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Процесс упал посреди записи события
+	f, err := os.OpenFile(filepath.Join(o.dir, segmentName(now)), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"type":"entry.created","entry":{"url":"tor`)
+	f.Close()
+
+	if err := o.Append(Event{Type: EntryCreated, Time: now, Entry: feed.Entry{Url: "second"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, c, err := o.read(cursor{}, 10)
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if len(records) != 2 || records[0].event.Entry.Url != "first" || records[1].event.Entry.Url != "second" {
+		t.Fatalf("read() = %+v, want first and second events", records)
+	}
+
+	// Позиция после прочитанных событий не возвращается к оборванной строке
+	records, _, err = o.read(c, 10)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("read() after end = %v, %v, want no events", records, err)
+	}
+}
+
+func TestPendingRecovery(t *testing.T) {
+	o, err := OpenOutbox(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := NewBus(o, 1)
+
+	stored, err := bus.Prepare(Event{Type: EntryCreated, Entry: feed.Entry{Url: "stored"}}, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost, err := bus.Prepare(Event{Type: EntryCreated, Entry: feed.Entry{Url: "lost"}}, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Обновление ранее сохраненной записи не удалось, в хранилище осталась прежняя версия
+	failed, err := bus.Prepare(Event{Type: EntryUpdated, Entry: feed.Entry{Url: "failed"}}, "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := bus.Prepare(Event{Type: EntryCreated, Entry: feed.Entry{Url: "fresh"}}, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Отметки stored и lost остались от упавшего процесса
+	old := time.Now().Add(-2 * pendingGrace)
+	os.Chtimes(stored.path, old, old)
+	os.Chtimes(lost.path, old, old)
+	os.Chtimes(failed.path, old, old)
+
+	versions := map[string]string{"stored": "v1", "failed": "v1"}
+	bus.WithRecovery(func(ctx context.Context, url string, hash string) (bool, error) {
+		v, ok := versions[url]
+		return ok && (hash == "" || hash == v), nil
+	})
+	if err := bus.recover(context.Background()); err != nil {
+		t.Fatalf("recover() error = %v", err)
+	}
+
+	records, _, err := o.read(cursor{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].event.Entry.Url != "stored" {
+		t.Fatalf("recovered events = %+v, want only stored", records)
+	}
+	for _, p := range []*Pending{stored, lost, failed} {
+		if _, err := os.Stat(p.path); !os.IsNotExist(err) {
+			t.Errorf("pending %v is not removed", p.ev.Entry.Url)
+		}
+	}
+	if _, err := os.Stat(fresh.path); err != nil {
+		t.Errorf("fresh pending event is removed: %v", err)
+	}
+
+	if err := fresh.Publish(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fresh.path); !os.IsNotExist(err) {
+		t.Error("published pending event is not removed")
+	}
+}
@@ -0,0 +1,151 @@
+package events
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+)
+
+const (
+	// pendingDir каталог журнала с отметками о событиях, которые еще не записаны в журнал
+	pendingDir = "pending"
+	// pendingGrace возраст отметки, после которого событие считается потерянным и публикуется при восстановлении.
+	// Более новые отметки могут принадлежать записям, которые еще сохраняются.
+	pendingGrace = 10 * time.Minute
+)
+
+// Exists сообщает, сохранена ли запись url в хранилище, а если hash не пуст — сохранена ли
+// версия записи с отпечатком фрагментов hash, см. ChunksHash
+type Exists func(ctx context.Context, url string, hash string) (bool, error)
+
+// Pending событие, подготовленное до записи в мантикору. Отметка о событии сохраняется в журнале,
+// пока событие не будет записано в журнал, поэтому событие не теряется, если процесс завершится
+// между записью в мантикору и записью в журнал.
+type Pending struct {
+	bus  *Bus
+	ev   Event
+	path string
+}
+
+// marker отметка о событии и отпечаток фрагментов записи, которые должны быть сохранены в мантикору
+type marker struct {
+	Event
+	Hash string `json:"hash,omitempty"`
+}
+
+// Prepare сохраняет отметку о событии ev, которое публикуется после записи в мантикору методом Pending.Publish.
+// hash отпечаток сохраняемых фрагментов записи, по которому при восстановлении событие отличается от неудачного
+// обновления ранее сохраненной записи, пустой hash — без проверки версии.
+// Если отметку сохранить не удалось, запись не должна сохраняться в мантикору. Для nil шины возвращает nil.
+func (b *Bus) Prepare(ev Event, hash string) (*Pending, error) {
+	if b == nil {
+		return nil, nil
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	p := &Pending{bus: b, ev: ev}
+	if b.outbox == nil {
+		return p, nil
+	}
+
+	dir := filepath.Join(b.outbox.dir, pendingDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create pending dir: %v", err)
+	}
+	sum := sha1.Sum([]byte(ev.Entry.Url))
+	p.path = filepath.Join(dir, fmt.Sprintf("%s-%d.json", hex.EncodeToString(sum[:8]), ev.Time.UnixNano()))
+	if err := jsonfile.Write(p.path, marker{Event: ev, Hash: hash}); err != nil {
+		return nil, fmt.Errorf("failed to save pending %v event: %v", ev.Type, err)
+	}
+	return p, nil
+}
+
+// Publish публикует событие и удаляет отметку о нем. Если событие не удалось записать в журнал,
+// отметка остается, и событие будет опубликовано при восстановлении.
+func (p *Pending) Publish(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.bus.Publish(ctx, p.ev); err != nil {
+		return err
+	}
+	return p.Cancel()
+}
+
+// Cancel удаляет отметку о событии, если запись не была сохранена
+func (p *Pending) Cancel() error {
+	if p == nil || p.path == "" {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// WithRecovery задает проверку наличия записи в хранилище, с которой служба публикует события,
+// потерянные между записью в мантикору и записью в журнал. Вызывается до Start.
+func (b *Bus) WithRecovery(exists Exists) *Bus {
+	b.exists = exists
+	return b
+}
+
+// recover публикует события по отметкам старше pendingGrace, если в хранилище сохранена версия записи
+// из отметки, и удаляет отметки событий, записи которых сохранить не удалось
+func (b *Bus) recover(ctx context.Context) error {
+	if b.exists == nil {
+		return nil
+	}
+
+	dir := filepath.Join(b.outbox.dir, pendingDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < pendingGrace {
+			continue
+		}
+
+		p := &Pending{bus: b, path: filepath.Join(dir, e.Name())}
+		var m marker
+		if _, err := jsonfile.Read(p.path, &m); err != nil {
+			log.Printf("events: removing broken pending event %v: %v", e.Name(), err)
+			p.Cancel()
+			continue
+		}
+		p.ev = m.Event
+
+		stored, err := b.exists(ctx, p.ev.Entry.Url, m.Hash)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			p.Cancel()
+			continue
+		}
+		log.Printf("events: recovering %v event for %v", p.ev.Type, p.ev.Entry.Url)
+		if err := p.Publish(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
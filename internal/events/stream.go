@@ -0,0 +1,104 @@
+package events
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tailBlock размер блока, которым конец сегмента читается в поисках последней полной строки
+const tailBlock = 4096
+
+// Stream читает события журнала без сохранения позиции, например, для трансляции событий клиентам.
+// Сегменты журнала читатель не удерживает: после удаления прочитанного сегмента чтение продолжается со следующего.
+type Stream struct {
+	outbox *Outbox
+	c      cursor
+}
+
+// Stream возвращает читателя событий, следующих за событием с идентификатором lastID.
+// Если lastID пуст или не найден в журнале, читаются только события, опубликованные после вызова.
+func (o *Outbox) Stream(lastID string) (*Stream, error) {
+	if c, ok := parseID(lastID); ok {
+		rs, next, err := o.read(c, 1)
+		if err == nil && len(rs) == 1 && rs[0].event.ID == lastID {
+			return &Stream{outbox: o, c: next}, nil
+		}
+	}
+
+	c, err := o.tail()
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{outbox: o, c: c}, nil
+}
+
+// Next возвращает не больше limit очередных событий журнала
+func (s *Stream) Next(limit int) ([]Event, error) {
+	rs, c, err := s.outbox.read(s.c, limit)
+	s.c = c
+
+	evs := make([]Event, 0, len(rs))
+	for _, r := range rs {
+		evs = append(evs, r.event)
+	}
+	return evs, err
+}
+
+// tail возвращает позицию после последнего полностью записанного события журнала
+func (o *Outbox) tail() (cursor, error) {
+	segments, err := o.segments()
+	if err != nil || len(segments) == 0 {
+		return cursor{}, err
+	}
+	last := segments[len(segments)-1]
+
+	f, err := os.Open(filepath.Join(o.dir, last))
+	if err != nil {
+		return cursor{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return cursor{}, err
+	}
+
+	// Ищем конец последней полной строки, неполная строка еще дописывается
+	buf := make([]byte, tailBlock)
+	for end := stat.Size(); end > 0; {
+		start := end - tailBlock
+		if start < 0 {
+			start = 0
+		}
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return cursor{}, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return cursor{Segment: last, Offset: start + int64(i) + 1}, nil
+		}
+		end = start
+	}
+	return cursor{Segment: last}, nil
+}
+
+// parseID возвращает позицию события по его идентификатору events-YYYYMMDD:offset
+func parseID(id string) (cursor, bool) {
+	name, offset, ok := strings.Cut(id, ":")
+	if !ok || !strings.HasPrefix(name, segmentPrefix) {
+		return cursor{}, false
+	}
+	n, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil || n < 0 {
+		return cursor{}, false
+	}
+	segment := name + segmentSuffix
+	if _, err := segmentDay(segment); err != nil {
+		return cursor{}, false
+	}
+	return cursor{Segment: segment, Offset: n}, true
+}
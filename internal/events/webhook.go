@@ -0,0 +1,49 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/alerts"
+	"github.com/terratensor/feed-parser/internal/config"
+)
+
+// WebhookHandler возвращает обработчик, который отправляет событие на вебхук POST запросом в формате JSON.
+// Если у вебхука задан secret, тело запроса подписывается так же, как оповещения.
+func WebhookHandler(w config.Webhook, timeout time.Duration) Handler {
+	client := &http.Client{Timeout: timeout}
+
+	return func(ctx context.Context, ev Event) error {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Feed-Event", string(ev.Type))
+		if w.Secret != "" {
+			req.Header.Set(alerts.SignatureHeader, "sha256="+alerts.Sign(body, w.Secret))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %v: unexpected status %v", w.Name, resp.Status)
+		}
+		return nil
+	}
+}
@@ -1,67 +1,305 @@
 package indexnow
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
 )
 
+// IndexNow накапливает адреса страниц и отправляет их пакетами по протоколу IndexNow
+// во все поисковые системы из списка endpoints. Ключ берется из переменной INDEX_NOW_KEY.
+// Очередь адресов сохраняется в файл queue: адрес удаляется из очереди поисковой системы
+// только после успешной отправки, поэтому адреса не теряются при ошибках и перезапусках.
 type IndexNow struct {
-	Key    string
-	client *http.Client
+	Key     string
+	opts    config.IndexNowOptions
+	client  *http.Client
+	mu      sync.Mutex
+	queue   map[string]map[string][]string // Адреса, ожидающие отправки, по endpoint и хостам
+	sending map[string]bool                // Отправляемые сейчас адреса по ключу endpoint и адреса
+	count   int
+	timer   *time.Timer
+	wg      sync.WaitGroup
+}
+
+// request тело пакетного запроса IndexNow
+type request struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation,omitempty"`
+	URLList     []string `json:"urlList"`
 }
 
-func NewIndexNow(enabled bool) *IndexNow {
+// NewIndexNow создает очередь IndexNow и загружает из файла queue адреса, не отправленные до перезапуска
+func NewIndexNow(enabled bool, opts config.IndexNowOptions) *IndexNow {
 	if !enabled {
 		return nil
 	}
 	key := os.Getenv("INDEX_NOW_KEY")
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout: opts.Timeout,
+	}
+	i := &IndexNow{
+		Key:     key,
+		opts:    opts,
+		client:  client,
+		queue:   make(map[string]map[string][]string),
+		sending: make(map[string]bool),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.Queue), 0o755); err != nil {
+		log.Printf("indexNow: failed to create queue dir: %v", err)
+	}
+	if _, err := jsonfile.Read(opts.Queue, &i.queue); err != nil {
+		log.Printf("indexNow: failed to load queue: %v", err)
+	}
+	if n := i.queued(); n > 0 {
+		log.Printf("indexNow: в очереди %d адресов, не отправленных до перезапуска", n)
+		i.timer = time.AfterFunc(opts.BatchWindow, i.Flush)
+	}
+	return i
+}
+
+// Submit добавляет адрес в очередь каждой поисковой системы и сохраняет очередь в файл.
+// Ошибка возвращается, если очередь не удалось сохранить. Очередь отправляется через batch_window
+// после первого добавленного адреса или сразу, когда в ней набирается batch_size адресов.
+func (i *IndexNow) Submit(link string) error {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("ошибка, некорректный url %v: %v", link, err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	added := false
+	for _, endpoint := range i.opts.Endpoints {
+		hosts := i.queue[endpoint]
+		if hosts == nil {
+			hosts = make(map[string][]string)
+			i.queue[endpoint] = hosts
+		}
+		if contains(hosts[u.Host], link) {
+			continue
+		}
+		hosts[u.Host] = append(hosts[u.Host], link)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	if err := i.save(); err != nil {
+		return fmt.Errorf("failed to save indexNow queue: %v", err)
+	}
+	i.count++
+
+	if i.count >= i.opts.BatchSize {
+		i.flushLocked()
+		return nil
+	}
+	if i.timer == nil {
+		i.timer = time.AfterFunc(i.opts.BatchWindow, i.Flush)
+	}
+	return nil
+}
+
+// Flush отправляет накопленные адреса, не дожидаясь окончания окна
+func (i *IndexNow) Flush() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.flushLocked()
+}
+
+// Close отправляет накопленные адреса и дожидается завершения всех отправок.
+// Адреса, которые не удалось отправить, остаются в файле очереди до следующего запуска.
+func (i *IndexNow) Close() {
+	i.Flush()
+	i.wg.Wait()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.timer != nil {
+		i.timer.Stop()
+		i.timer = nil
 	}
-	return &IndexNow{
-		Key:    key,
-		client: client,
+}
+
+// flushLocked отправляет адреса очереди, которые еще не отправляются, пакетами не больше batch_size
+func (i *IndexNow) flushLocked() {
+	if i.timer != nil {
+		i.timer.Stop()
+		i.timer = nil
+	}
+	i.count = 0
+
+	for endpoint, hosts := range i.queue {
+		for host, links := range hosts {
+			var batch []string
+			for _, link := range links {
+				if i.sending[endpoint+" "+link] {
+					continue
+				}
+				i.sending[endpoint+" "+link] = true
+				batch = append(batch, link)
+				if len(batch) == i.opts.BatchSize {
+					i.sendAsync(endpoint, host, batch)
+					batch = nil
+				}
+			}
+			if len(batch) > 0 {
+				i.sendAsync(endpoint, host, batch)
+			}
+		}
+	}
+}
+
+// sendAsync отправляет пакет адресов в фоне. После успешной отправки и после отказа, который
+// не имеет смысла повторять, адреса удаляются из очереди, иначе остаются в ней до следующей отправки.
+func (i *IndexNow) sendAsync(endpoint, host string, links []string) {
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		err := i.send(endpoint, request{Host: host, Key: i.Key, KeyLocation: i.opts.KeyLocation, URLList: links})
+		var retryable *retryableError
+		keep := errors.As(err, &retryable)
+		if err != nil {
+			log.Printf("indexNow error: %v", err)
+		}
+
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for _, link := range links {
+			delete(i.sending, endpoint+" "+link)
+		}
+		if keep {
+			// Повторяем отправку позже, если до этого не будет добавлен новый адрес
+			if i.timer == nil {
+				i.timer = time.AfterFunc(i.opts.RetryDelay, i.Flush)
+			}
+			return
+		}
+		i.remove(endpoint, host, links)
+		if err := i.save(); err != nil {
+			log.Printf("indexNow: failed to save queue: %v", err)
+		}
+	}()
+}
+
+// remove удаляет отправленные адреса из очереди endpoint
+func (i *IndexNow) remove(endpoint, host string, links []string) {
+	hosts := i.queue[endpoint]
+	var rest []string
+	for _, link := range hosts[host] {
+		if !contains(links, link) {
+			rest = append(rest, link)
+		}
+	}
+	if len(rest) > 0 {
+		hosts[host] = rest
+		return
+	}
+	delete(hosts, host)
+	if len(hosts) == 0 {
+		delete(i.queue, endpoint)
+	}
+}
+
+// queued возвращает количество адресов в очереди всех поисковых систем
+func (i *IndexNow) queued() int {
+	n := 0
+	for _, hosts := range i.queue {
+		for _, links := range hosts {
+			n += len(links)
+		}
 	}
+	return n
 }
 
-func (i *IndexNow) Get(link string) error {
+// save записывает очередь в файл queue
+func (i *IndexNow) save() error {
+	return jsonfile.Write(i.opts.Queue, i.queue)
+}
 
-	var u = url.URL{
-		Scheme: "https",
-		Host:   "yandex.com",
-		Path:   "indexnow",
+func contains(links []string, link string) bool {
+	for _, l := range links {
+		if l == link {
+			return true
+		}
 	}
+	return false
+}
 
-	q := u.Query()
-	q.Set("key", i.Key)
-	q.Set("url", link)
-	u.RawQuery = q.Encode()
+// retryableError ошибка отправки, после которой адреса стоит отправить повторно
+type retryableError struct {
+	err error
+}
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// send отправляет пакет адресов в endpoint, повторяя попытки при ответах 429 и 5xx с удваивающейся задержкой
+func (i *IndexNow) send(endpoint string, r request) error {
+	body, err := json.Marshal(r)
 	if err != nil {
-		return fmt.Errorf("ошибка при создании url: %v", err)
+		return err
 	}
 
-	resp, err := i.client.Do(req)
+	delay := i.opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := i.post(endpoint, body)
+		if err == nil {
+			log.Printf("🚩🚩 IndexNow %v: передано адресов %d", endpoint, len(r.URLList))
+			return nil
+		}
+		if !retry {
+			return fmt.Errorf("%v: %v", endpoint, err)
+		}
+		if attempt >= i.opts.Retries {
+			return &retryableError{err: fmt.Errorf("%v: %v", endpoint, err)}
+		}
+		log.Printf("indexNow %v: попытка %d не удалась: %v, повтор через %v", endpoint, attempt+1, err, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
 
+// post выполняет запрос и сообщает, имеет ли смысл повторить его при ошибке
+func (i *IndexNow) post(endpoint string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("ошибка при отправке url: %v", err)
+		return false, fmt.Errorf("ошибка при создании запроса: %v", err)
 	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		log.Printf("🚩🚩 IndexNow url: %v", link)
-		log.Printf("🚩🚩 feed.svodd.ru url успешно передан: %v", link)
-		break
-	case http.StatusAccepted:
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("ошибка при отправке запроса: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return false, nil
+	case resp.StatusCode == http.StatusAccepted:
 		log.Println("новый ключ ожидает проверки")
-		break
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("код ответа %v", resp.StatusCode)
 	default:
-		return fmt.Errorf("ошибка, код ответа %v, подробнее: https://yandex.ru/support/webmaster/indexnow/reference/get-url.html", resp.StatusCode)
+		return false, fmt.Errorf("ошибка, код ответа %v, подробнее: https://www.indexnow.org/documentation", resp.StatusCode)
 	}
-	return nil
 }
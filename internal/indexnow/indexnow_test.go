@@ -0,0 +1,110 @@
+package indexnow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+)
+
+// testEndpoint API IndexNow, который отвечает кодом status и запоминает полученные адреса
+type testEndpoint struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	received []string
+}
+
+func newTestEndpoint(t *testing.T, status int) *testEndpoint {
+	e := &testEndpoint{status: status}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		json.NewDecoder(r.Body).Decode(&req)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.status == http.StatusOK {
+			e.received = append(e.received, req.URLList...)
+		}
+		w.WriteHeader(e.status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *testEndpoint) urls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.received...)
+}
+
+func testOptions(queue string, endpoints ...string) config.IndexNowOptions {
+	return config.IndexNowOptions{
+		Endpoints:   endpoints,
+		BatchWindow: time.Hour,
+		BatchSize:   100,
+		Retries:     0,
+		RetryDelay:  time.Hour,
+		Timeout:     time.Second,
+		Queue:       queue,
+	}
+}
+
+func TestIndexNowKeepsQueueOnFailure(t *testing.T) {
+	queue := filepath.Join(t.TempDir(), "indexnow", "queue.json")
+	const link = "https://feed.example/entry?url=1"
+
+	down := newTestEndpoint(t, http.StatusServiceUnavailable)
+	ok := newTestEndpoint(t, http.StatusOK)
+
+	i := NewIndexNow(true, testOptions(queue, down.URL, ok.URL))
+	if err := i.Submit(link); err != nil {
+		t.Fatal(err)
+	}
+	i.Close()
+
+	if got := ok.urls(); len(got) != 1 || got[0] != link {
+		t.Fatalf("available endpoint received %v, want [%v]", got, link)
+	}
+
+	// Адрес остается в очереди только для недоступной поисковой системы
+	restarted := NewIndexNow(true, testOptions(queue, down.URL, ok.URL))
+	if n := restarted.queued(); n != 1 {
+		t.Fatalf("queued after restart = %d, want 1", n)
+	}
+	if links := restarted.queue[down.URL]["feed.example"]; len(links) != 1 || links[0] != link {
+		t.Fatalf("queue = %v", restarted.queue)
+	}
+
+	down.mu.Lock()
+	down.status = http.StatusOK
+	down.mu.Unlock()
+	restarted.Close()
+
+	if got := down.urls(); len(got) != 1 || got[0] != link {
+		t.Errorf("endpoint received %v after restart, want [%v]", got, link)
+	}
+	if n := NewIndexNow(true, testOptions(queue, down.URL, ok.URL)).queued(); n != 0 {
+		t.Errorf("queued after successful send = %d, want 0", n)
+	}
+}
+
+func TestIndexNowDropsRejectedURLs(t *testing.T) {
+	queue := filepath.Join(t.TempDir(), "queue.json")
+	rejected := newTestEndpoint(t, http.StatusUnprocessableEntity)
+
+	i := NewIndexNow(true, testOptions(queue, rejected.URL))
+	if err := i.Submit("https://feed.example/entry?url=1"); err != nil {
+		t.Fatal(err)
+	}
+	i.Close()
+
+	if n := i.queued(); n != 0 {
+		t.Errorf("queued = %d, want rejected URL to be dropped", n)
+	}
+}
@@ -0,0 +1,39 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Read читает JSON файл filename в v. Если файла нет, возвращает false без ошибки.
+func Read(filename string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %v: %v", filename, err)
+	}
+	return true, nil
+}
+
+// Write записывает v во временный файл и переименовывает его в filename,
+// чтобы при падении процесса не остался наполовину записанный файл
+func Write(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,168 @@
+// Package langdetect определяет язык текста по частотам n-грамм символов
+package langdetect
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxN максимальная длина n-граммы
+	maxN = 3
+	// profileSize количество самых частых n-грамм в профиле языка
+	profileSize = 400
+	// maxRunes количество символов текста, по которым определяется язык
+	maxRunes = 3000
+	// minLetters минимальное количество букв в тексте, при котором язык определяется
+	minLetters = 20
+)
+
+// Result определенный язык и уверенность от 0 до 1
+type Result struct {
+	Lang       string
+	Confidence float64
+}
+
+type profile struct {
+	lang    string
+	weights map[string]float64
+	norm    float64
+}
+
+// profiles профили языков, строятся при инициализации пакета из samples
+var profiles []profile
+
+func init() {
+	for lang, text := range samples {
+		profiles = append(profiles, newProfile(lang, ngrams(text), profileSize))
+	}
+	// Порядок профилей не должен зависеть от порядка обхода map
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].lang < profiles[j].lang })
+}
+
+// Languages возвращает языки, которые умеет определять детектор
+func Languages() []string {
+	var langs []string
+	for _, p := range profiles {
+		langs = append(langs, p.lang)
+	}
+	return langs
+}
+
+// Detect определяет язык текста. Уверенность тем выше, чем сильнее сходство
+// с лучшим профилем отличается от сходства со вторым по близости профилем.
+// Для слишком короткого текста возвращает пустой результат.
+func Detect(text string) Result {
+	counts := ngrams(text)
+	if counts == nil {
+		return Result{}
+	}
+	doc := newProfile("", counts, 0)
+	if doc.norm == 0 {
+		return Result{}
+	}
+
+	best, second := Result{}, 0.0
+	for _, p := range profiles {
+		score := similarity(doc, p)
+		if score > best.Confidence {
+			second = best.Confidence
+			best = Result{Lang: p.lang, Confidence: score}
+		} else if score > second {
+			second = score
+		}
+	}
+	if best.Confidence == 0 {
+		return Result{}
+	}
+
+	best.Confidence = math.Round((1-second/best.Confidence)*1000) / 1000
+	return best
+}
+
+// ngrams считает n-граммы длиной от 1 до maxN в словах текста,
+// слова дополняются пробелами, чтобы учитывались начала и окончания слов
+func ngrams(text string) map[string]int {
+	counts := make(map[string]int)
+	letters := 0
+
+	var word []rune
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		padded := append(append([]rune{' '}, word...), ' ')
+		for n := 1; n <= maxN; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				g := string(padded[i : i+n])
+				if g == " " {
+					continue
+				}
+				counts[g]++
+			}
+		}
+		word = word[:0]
+	}
+
+	runes := 0
+	for _, r := range text {
+		if runes >= maxRunes {
+			break
+		}
+		runes++
+		if unicode.IsLetter(r) {
+			word = append(word, unicode.ToLower(r))
+			letters++
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	if letters < minLetters {
+		return nil
+	}
+	return counts
+}
+
+// newProfile оставляет size самых частых n-грамм, 0 — все,
+// и считает норму вектора частот
+func newProfile(lang string, counts map[string]int, size int) profile {
+	keys := make([]string, 0, len(counts))
+	for g := range counts {
+		keys = append(keys, g)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if size > 0 && len(keys) > size {
+		keys = keys[:size]
+	}
+
+	p := profile{lang: lang, weights: make(map[string]float64, len(keys))}
+	for _, g := range keys {
+		// Длинные n-граммы сильнее различают языки, чем отдельные буквы
+		w := math.Log1p(float64(counts[g])) * float64(len([]rune(strings.TrimSpace(g))))
+		p.weights[g] = w
+		p.norm += w * w
+	}
+	p.norm = math.Sqrt(p.norm)
+	return p
+}
+
+// similarity косинусная мера сходства профилей
+func similarity(a, b profile) float64 {
+	if a.norm == 0 || b.norm == 0 {
+		return 0
+	}
+	var dot float64
+	for g, w := range a.weights {
+		dot += w * b.weights[g]
+	}
+	return dot / (a.norm * b.norm)
+}
@@ -0,0 +1,92 @@
+package langdetect
+
+// samples тексты, по которым строятся n-граммные профили языков.
+// Тексты близки по тематике к материалам лент: официальные заявления,
+// переговоры, брифинги и новости.
+var samples = map[string]string{
+	"ru": `Министр иностранных дел Российской Федерации провел переговоры со своим коллегой.
+В ходе беседы стороны обсудили актуальные вопросы двусторонних отношений, а также ключевые темы международной
+и региональной повестки дня. Особое внимание было уделено развитию торгово-экономического сотрудничества,
+взаимодействию в энергетической сфере и гуманитарным связям. Президент России заявил, что наша страна
+открыта для диалога со всеми партнерами, которые готовы к равноправному и взаимовыгодному сотрудничеству.
+Он подчеркнул, что безопасность должна быть неделимой и что нельзя укреплять свою безопасность за счет других
+государств. На брифинге официальный представитель министерства ответила на вопросы журналистов о ситуации
+в регионе, о подготовке к предстоящему саммиту и о работе посольства. По ее словам, мы рассчитываем на то,
+что в ближайшее время будут приняты меры по урегулированию кризиса политическими и дипломатическими средствами.
+Министерство обороны сообщает, что подразделения продолжают выполнение задач, военнослужащие проходят
+подготовку на полигонах, проводятся учения с участием авиации и флота. Правительство утвердило программу
+поддержки семей, образования и здравоохранения, которая будет реализована в течение нескольких лет.
+Участники встречи договорились продолжить консультации и подписали совместное заявление о дальнейших шагах.`,
+
+	"en": `The Minister of Foreign Affairs of the Russian Federation held talks with his counterpart.
+During the conversation the parties discussed current issues of bilateral relations as well as key topics of the
+international and regional agenda. Particular attention was paid to the development of trade and economic
+cooperation, interaction in the energy sector and humanitarian ties. The President said that our country is open
+to dialogue with all partners who are ready for equal and mutually beneficial cooperation. He stressed that
+security should be indivisible and that no state can strengthen its own security at the expense of others.
+At the briefing the official spokeswoman of the ministry answered questions from journalists about the situation
+in the region, the preparations for the upcoming summit and the work of the embassy. According to her, we expect
+that measures will be taken in the near future to settle the crisis by political and diplomatic means.
+The Ministry of Defence reports that the units continue to carry out their tasks, the servicemen are training
+at the ranges, and exercises are being held with the participation of aviation and the navy. The government has
+approved a programme to support families, education and healthcare, which will be implemented over several years.
+The participants of the meeting agreed to continue consultations and signed a joint statement on further steps.`,
+
+	"de": `Der Außenminister der Russischen Föderation führte Gespräche mit seinem Amtskollegen.
+Während des Gesprächs erörterten die Seiten aktuelle Fragen der bilateralen Beziehungen sowie wichtige Themen der
+internationalen und regionalen Tagesordnung. Besondere Aufmerksamkeit wurde der Entwicklung der handelspolitischen
+und wirtschaftlichen Zusammenarbeit, der Kooperation im Energiebereich und den humanitären Verbindungen gewidmet.
+Der Präsident erklärte, dass unser Land für den Dialog mit allen Partnern offen ist, die zu einer gleichberechtigten
+und für beide Seiten vorteilhaften Zusammenarbeit bereit sind. Er betonte, dass die Sicherheit unteilbar sein muss
+und dass kein Staat seine eigene Sicherheit auf Kosten anderer Staaten stärken darf. Bei dem Briefing beantwortete
+die offizielle Sprecherin des Ministeriums die Fragen der Journalisten über die Lage in der Region, über die
+Vorbereitung des bevorstehenden Gipfeltreffens und über die Arbeit der Botschaft. Ihren Worten nach erwarten wir,
+dass in nächster Zeit Maßnahmen zur Beilegung der Krise mit politischen und diplomatischen Mitteln ergriffen werden.
+Das Verteidigungsministerium teilt mit, dass die Einheiten ihre Aufgaben weiter erfüllen und die Soldaten auf den
+Truppenübungsplätzen ausgebildet werden. Die Regierung hat ein Programm zur Unterstützung von Familien, Bildung und
+Gesundheit beschlossen, das über mehrere Jahre umgesetzt wird. Die Teilnehmer des Treffens vereinbarten, die
+Konsultationen fortzusetzen, und unterzeichneten eine gemeinsame Erklärung über die weiteren Schritte.`,
+
+	"fr": `Le ministre des Affaires étrangères de la Fédération de Russie a eu des entretiens avec son homologue.
+Au cours de la conversation, les parties ont examiné les questions actuelles des relations bilatérales ainsi que
+les thèmes clés de l'agenda international et régional. Une attention particulière a été accordée au développement
+de la coopération commerciale et économique, à l'interaction dans le domaine de l'énergie et aux liens humanitaires.
+Le président a déclaré que notre pays est ouvert au dialogue avec tous les partenaires qui sont prêts à une
+coopération égale et mutuellement avantageuse. Il a souligné que la sécurité doit être indivisible et qu'aucun État
+ne peut renforcer sa propre sécurité aux dépens des autres. Lors du point de presse, la porte-parole officielle du
+ministère a répondu aux questions des journalistes sur la situation dans la région, sur la préparation du prochain
+sommet et sur le travail de l'ambassade. Selon elle, nous espérons que des mesures seront prises dans un proche
+avenir pour régler la crise par des moyens politiques et diplomatiques. Le ministère de la Défense indique que les
+unités continuent d'accomplir leurs tâches et que les militaires suivent une formation sur les terrains d'exercice.
+Le gouvernement a approuvé un programme de soutien aux familles, à l'éducation et à la santé, qui sera mis en œuvre
+pendant plusieurs années. Les participants de la réunion sont convenus de poursuivre les consultations et ont signé
+une déclaration commune sur les prochaines étapes.`,
+
+	"es": `El ministro de Asuntos Exteriores de la Federación de Rusia mantuvo conversaciones con su homólogo.
+Durante la conversación las partes examinaron las cuestiones actuales de las relaciones bilaterales, así como los
+temas clave de la agenda internacional y regional. Se prestó especial atención al desarrollo de la cooperación
+comercial y económica, a la interacción en el sector energético y a los lazos humanitarios. El presidente declaró
+que nuestro país está abierto al diálogo con todos los socios que estén dispuestos a una cooperación igualitaria y
+mutuamente beneficiosa. Subrayó que la seguridad debe ser indivisible y que ningún Estado puede fortalecer su propia
+seguridad a costa de los demás. En la rueda de prensa, la portavoz oficial del ministerio respondió a las preguntas
+de los periodistas sobre la situación en la región, sobre la preparación de la próxima cumbre y sobre el trabajo de
+la embajada. Según sus palabras, esperamos que en un futuro próximo se tomen medidas para resolver la crisis por
+medios políticos y diplomáticos. El Ministerio de Defensa informa que las unidades siguen cumpliendo sus tareas y
+que los militares reciben entrenamiento en los polígonos. El Gobierno aprobó un programa de apoyo a las familias, la
+educación y la sanidad, que se llevará a cabo durante varios años. Los participantes de la reunión acordaron
+continuar las consultas y firmaron una declaración conjunta sobre los próximos pasos.`,
+
+	"pt": `O ministro dos Negócios Estrangeiros da Federação da Rússia manteve conversações com o seu homólogo.
+Durante a conversa, as partes discutiram as questões atuais das relações bilaterais, bem como os principais temas
+da agenda internacional e regional. Foi dada especial atenção ao desenvolvimento da cooperação comercial e
+económica, à interação no setor da energia e aos laços humanitários. O presidente afirmou que o nosso país está
+aberto ao diálogo com todos os parceiros que estejam prontos para uma cooperação igual e mutuamente vantajosa.
+Ele sublinhou que a segurança deve ser indivisível e que nenhum Estado pode reforçar a sua própria segurança à
+custa dos outros. No briefing, a porta-voz oficial do ministério respondeu às perguntas dos jornalistas sobre a
+situação na região, sobre a preparação da próxima cimeira e sobre o trabalho da embaixada. Segundo ela, esperamos
+que num futuro próximo sejam tomadas medidas para resolver a crise por meios políticos e diplomáticos. O Ministério
+da Defesa informa que as unidades continuam a cumprir as suas tarefas e que os militares estão a receber formação
+nos campos de treino. O Governo aprovou um programa de apoio às famílias, à educação e à saúde, que será executado
+ao longo de vários anos. Os participantes da reunião acordaram em continuar as consultas e assinaram uma
+declaração conjunta sobre os próximos passos.`,
+}
@@ -0,0 +1,48 @@
+package sentence
+
+// abbreviations сокращения, после точки в которых предложение не заканчивается.
+// Сокращения записаны в нижнем регистре без завершающей точки.
+var abbreviations = map[string][]string{
+	"ru": {
+		"т", "е", "т.е", "т.д", "т.п", "т.к", "т.н", "т.ч", "и.о", "н.э", "др",
+		"г", "гг", "в", "вв", "им", "ул", "пр", "просп", "пл", "пер", "д", "кв", "обл", "р-н", "пос", "ст",
+		"стр", "рис", "табл", "см", "ср", "гл", "ч", "п", "пп", "ред", "изд", "вып",
+		"руб", "коп", "долл", "тыс", "млн", "млрд", "трлн", "ок", "напр", "мин", "сек",
+		"проф", "акад", "доц", "зам", "нач", "ген", "англ", "лат",
+	},
+	"en": {
+		"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "vs", "etc", "e.g", "i.e", "cf", "approx",
+		"u.s", "u.k", "u.n", "inc", "ltd", "co", "corp", "dept", "fig", "no", "vol",
+		"gen", "col", "lt", "sgt", "capt", "gov", "sen", "rep", "pres",
+		"jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec",
+	},
+	"de": {
+		"z.b", "d.h", "u.a", "usw", "bzw", "ca", "dr", "prof", "nr", "str", "s", "vgl", "evtl", "ggf",
+		"inkl", "bspw", "sog", "jh", "hr", "fr", "abs", "mio", "mrd", "bzgl", "z.t", "u.u",
+	},
+	"fr": {
+		"m", "mm", "mme", "mlle", "dr", "pr", "etc", "p.ex", "cf", "av", "bd", "st", "ste",
+		"env", "mio", "mrd", "n", "p", "vol", "éd",
+	},
+	"es": {
+		"sr", "sra", "srta", "sres", "dr", "dra", "d", "dña", "ud", "uds", "prof", "etc", "p.ej",
+		"pág", "núm", "av", "avda", "ee.uu", "s.a", "aprox", "vol",
+	},
+	"pt": {
+		"sr", "sra", "srta", "dr", "dra", "prof", "etc", "p.ex", "ex", "av", "pág", "núm",
+		"eua", "s.a", "aprox", "vol",
+	},
+}
+
+// abbreviationSet сокращения по языкам в виде множеств
+var abbreviationSet = func() map[string]map[string]bool {
+	result := make(map[string]map[string]bool, len(abbreviations))
+	for lang, list := range abbreviations {
+		set := make(map[string]bool, len(list))
+		for _, a := range list {
+			set[a] = true
+		}
+		result[lang] = set
+	}
+	return result
+}()
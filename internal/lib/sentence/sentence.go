@@ -0,0 +1,142 @@
+// Package sentence делит текст на предложения с учетом сокращений,
+// инициалов и чисел для языков ru, en, de, fr, es, pt.
+package sentence
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// terminals знаки, которыми может заканчиваться предложение
+const terminals = ".!?…"
+
+// closers закрывающие кавычки и скобки, которые относятся к концу предложения
+const closers = `"'»”’)]`
+
+// Split делит текст на предложения. Пробелы после конца предложения
+// остаются в конце предложения, так что склейка результата дает исходный текст.
+// Язык задается кодом вида "ru" или "ru-RU", для неизвестного языка
+// учитываются только инициалы и сокращения с внутренними точками.
+func Split(text, lang string) []string {
+	var result []string
+	runes := []rune(text)
+	set := abbreviationSet[normalize(lang)]
+
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune(terminals, runes[i]) {
+			continue
+		}
+		// Включаем в предложение повторяющиеся знаки и закрывающие кавычки
+		end := i + 1
+		for end < len(runes) && (strings.ContainsRune(terminals, runes[end]) || strings.ContainsRune(closers, runes[end])) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			i = end - 1
+			continue
+		}
+		if runes[end-1] == '.' || runes[i] == '.' {
+			if isAbbreviation(runes[start:i], set) || nextIsLower(runes[end:]) {
+				i = end - 1
+				continue
+			}
+		}
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		result = append(result, string(runes[start:end]))
+		start = end
+		i = end - 1
+	}
+	if start < len(runes) {
+		result = append(result, string(runes[start:]))
+	}
+
+	return result
+}
+
+// Ends сообщает, что текст заканчивается концом предложения,
+// за которым следует пробел
+func Ends(text, lang string) bool {
+	trimmed := strings.TrimRightFunc(text, unicode.IsSpace)
+	if trimmed == "" || trimmed == text {
+		return false
+	}
+	parts := Split(text+"X", lang)
+	return len(parts) > 1 && parts[len(parts)-1] == "X"
+}
+
+// First возвращает первые предложения текста общей длиной не более max символов.
+// Если первое предложение длиннее max, оно обрезается по границе слова.
+func First(text, lang string, max int) string {
+	var builder strings.Builder
+	for _, s := range Split(strings.TrimSpace(text), lang) {
+		if builder.Len() > 0 && utf8.RuneCountInString(builder.String())+utf8.RuneCountInString(s) > max {
+			break
+		}
+		builder.WriteString(s)
+	}
+
+	result := strings.TrimSpace(builder.String())
+	if utf8.RuneCountInString(result) <= max {
+		return result
+	}
+
+	runes := []rune(result)[:max]
+	if n := strings.LastIndexFunc(string(runes), unicode.IsSpace); n > 0 {
+		return strings.TrimSpace(string(runes)[:n]) + "…"
+	}
+	return string(runes) + "…"
+}
+
+// isAbbreviation сообщает, что слово перед точкой является сокращением или инициалом
+func isAbbreviation(before []rune, set map[string]bool) bool {
+	n := len(before)
+	for n > 0 && !unicode.IsSpace(before[n-1]) && !strings.ContainsRune(`("'«“[`, before[n-1]) {
+		n--
+	}
+	word := string(before[n:])
+	if word == "" {
+		return false
+	}
+	// Инициал: одна заглавная буква, например «В. Путин»
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+		return true
+	}
+	lower := strings.ToLower(word)
+	if set[lower] {
+		return true
+	}
+	// Сокращения с внутренними точками: «т.е», «U.S», «В.В»
+	if strings.Contains(lower, ".") {
+		for _, part := range strings.Split(lower, ".") {
+			if utf8.RuneCountInString(part) > 2 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// nextIsLower сообщает, что следующее слово начинается со строчной буквы
+func nextIsLower(after []rune) bool {
+	for _, r := range after {
+		if unicode.IsSpace(r) || strings.ContainsRune(`("'«“`, r) {
+			continue
+		}
+		return unicode.IsLower(r)
+	}
+	return false
+}
+
+// normalize приводит код языка к виду "ru"
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,64 @@
+// Package simhash вычисляет отпечатки SimHash текстов для поиска почти одинаковых текстов
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minWord минимальная длина слова, более короткие слова и предлоги не учитываются
+	minWord = 3
+	// stemSize количество начальных символов слова, по которым слова считаются одинаковыми
+	stemSize = 5
+)
+
+// Fingerprint возвращает 64-битный отпечаток SimHash текста по словам.
+// Признаками служат отдельные слова, а не шинглы: на коротких текстах шинглы
+// слишком чувствительны к перестановке и замене слов при пересказе.
+// Регистр и знаки препинания на отпечаток не влияют. Для пустого текста возвращает 0.
+func Fingerprint(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, w := range words {
+		r := []rune(w)
+		if len(r) < minWord {
+			continue
+		}
+		// Начало слова вместо слова целиком, чтобы совпадали формы одного слова
+		if len(r) > stemSize {
+			r = r[:stemSize]
+		}
+		h := fnv.New64a()
+		h.Write([]byte(string(r)))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var fp uint64
+	for i, w := range weights {
+		if w > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+	return fp
+}
+
+// Distance расстояние Хэмминга между отпечатками
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -23,7 +23,7 @@ func StripHtmlTags(s string) string {
 
 	for i, c := range s {
 		// If this is the last character and we are not in an HTML tag, save it.
-		if (i+1) == len(s) && end >= start {
+		if i+utf8.RuneLen(c) == len(s) && end >= start {
 			builder.WriteString(s[end:])
 		}
 
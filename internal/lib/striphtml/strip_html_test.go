@@ -0,0 +1,29 @@
+package striphtml
+
+import "testing"
+
+func TestStripHtmlTags(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain ascii", "hello", "hello"},
+		{"tags", "hello <b>world</b> again", "hello world again"},
+		{"nested start", "a <<br> b", "a  b"},
+		{"unclosed tag", "text <b", "text "},
+		{"multibyte text", "Москва", "Москва"},
+		{"multibyte tail after tag", "<p>Заявление</p> МИД России", "Заявление МИД России"},
+		{"single multibyte rune after tag", "<br>ж", "ж"},
+		{"emoji tail", "<i>итог</i> 🇷🇺", "итог 🇷🇺"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripHtmlTags(tt.in); got != tt.want {
+				t.Errorf("StripHtmlTags(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Package linker объединяет переводы одного материала на разные языки в группы
+package linker
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// defaultPatterns шаблоны идентификатора материала в адресе записи по ресурсам.
+// Кремль публикует переводы на en.kremlin.ru с тем же номером, МИД — с тем же номером
+// в адресе после кода языка.
+var defaultPatterns = map[int]string{
+	1: `kremlin\.ru/events/.*?/(\d+)/?$`,
+	2: `mid\.ru/[a-z]{2}/.*?/(\d+)/?$`,
+}
+
+// Linker присваивает записям идентификатор группы переводов
+type Linker struct {
+	store    feed.StorageInterface
+	cfg      config.Linker
+	patterns map[string]*regexp.Regexp
+}
+
+// New создает Linker, шаблоны адресов берутся из id_pattern лент,
+// для лент без шаблона — из шаблонов по умолчанию для ресурса
+func New(store feed.StorageInterface, cfg *config.Config) (*Linker, error) {
+	l := &Linker{
+		store:    store,
+		cfg:      cfg.Linker,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+
+	for resourceID, pattern := range defaultPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		l.patterns[patternKey(resourceID, "")] = re
+	}
+	for _, p := range cfg.Parsers {
+		if p.IDPattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.IDPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id_pattern of %v: %v", p.Url, err)
+		}
+		l.patterns[patternKey(p.ResourceID, p.Lang)] = re
+	}
+
+	return l, nil
+}
+
+func patternKey(resourceID int, lang string) string {
+	return fmt.Sprintf("%d/%s", resourceID, lang)
+}
+
+// Link присваивает записи e идентификатор группы. Группа определяется по номеру материала
+// в адресе, если адрес не подходит под шаблон ресурса, то ищется запись на другом языке,
+// опубликованная в пределах окна window, с похожим заголовком. Если такой записи нет,
+// запись образует собственную группу. Уже присвоенная группа не изменяется.
+func (l *Linker) Link(ctx context.Context, e *feed.Entry) error {
+	if e.GroupID != 0 {
+		return nil
+	}
+	if id, ok := l.urlID(e); ok {
+		e.GroupID = groupID(fmt.Sprintf("%d:%s", e.ResourceID, id))
+		return nil
+	}
+
+	e.GroupID = groupID(e.Url)
+	if e.Published == nil || e.Published.IsZero() {
+		return nil
+	}
+
+	candidates, err := l.store.FindPublished(ctx, e.ResourceID, e.Published.Add(-l.cfg.Window), e.Published.Add(l.cfg.Window))
+	if err != nil {
+		return err
+	}
+
+	best := 0.0
+	for _, c := range candidates {
+		if c.Url == e.Url || c.Language == e.Language || c.GroupID == 0 {
+			continue
+		}
+		if s := TitleSimilarity(e.Title, c.Title); s >= l.cfg.MinSimilarity && s > best {
+			best = s
+			e.GroupID = c.GroupID
+		}
+	}
+	return nil
+}
+
+// urlID возвращает номер материала из адреса записи
+func (l *Linker) urlID(e *feed.Entry) (string, bool) {
+	re, ok := l.patterns[patternKey(e.ResourceID, e.Language)]
+	if !ok {
+		re, ok = l.patterns[patternKey(e.ResourceID, "")]
+	}
+	if !ok {
+		return "", false
+	}
+	m := re.FindStringSubmatch(e.Url)
+	if len(m) < 2 || m[1] == "" {
+		return "", false
+	}
+	return m[1], true
+}
+
+// groupID возвращает положительный идентификатор группы по ключу
+func groupID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64() >> 1)
+}
+
+// TitleSimilarity сходство заголовков на разных языках от 0 до 1 по коэффициенту Жаккара
+// множеств триграмм. Кириллица транслитерируется, поэтому совпадают имена, названия и числа.
+func TitleSimilarity(a, b string) float64 {
+	ta, tb := trigrams(translit(a)), trigrams(translit(b))
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for g := range ta {
+		if tb[g] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// trigrams триграммы слов текста длиной от трех символов
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r := []rune(w)
+		if len(r) < 3 {
+			continue
+		}
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+var translitTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// translit приводит текст к нижнему регистру, транслитерирует кириллицу
+// и убирает диакритические знаки распространенных латинских букв
+func translit(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if t, ok := translitTable[r]; ok {
+			sb.WriteString(t)
+			continue
+		}
+		switch r {
+		case 'á', 'à', 'â', 'ã', 'ä':
+			r = 'a'
+		case 'é', 'è', 'ê', 'ë':
+			r = 'e'
+		case 'í', 'ì', 'î', 'ï':
+			r = 'i'
+		case 'ó', 'ò', 'ô', 'õ', 'ö':
+			r = 'o'
+		case 'ú', 'ù', 'û', 'ü':
+			r = 'u'
+		case 'ç':
+			r = 'c'
+		case 'ñ':
+			r = 'n'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
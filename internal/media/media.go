@@ -0,0 +1,211 @@
+// Package media извлекает из html контента изображения, видео и вложения записи.
+package media
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"golang.org/x/net/html"
+)
+
+// documentExt расширения файлов, ссылки на которые считаются вложениями
+var documentExt = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".rtf": true, ".odt": true,
+	".xls": true, ".xlsx": true, ".ppt": true, ".pptx": true, ".zip": true,
+}
+
+// videoHosts сайты, встроенные плееры которых считаются видео
+var videoHosts = []string{"youtube.com", "youtu.be", "rutube.ru", "vk.com", "vkvideo.ru", "kremlin.ru"}
+
+// Extract возвращает медиа из html контента: изображения img, видео video и iframe,
+// аудио audio и ссылки на документы. Относительные адреса разрешаются относительно base.
+// Подписью изображения считается figcaption, alt или title.
+func Extract(content string, base string) []feed.Media {
+	baseURL, _ := url.Parse(base)
+
+	var result []feed.Media
+	// figure индекс первого медиа внутри открытого figure, -1 если figure не открыт
+	figure := -1
+	var caption strings.Builder
+	inCaption := false
+	// link открытая ссылка на документ, текст которой станет подписью
+	link := -1
+	var linkText strings.Builder
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.Data {
+			case "figure":
+				figure = len(result)
+			case "figcaption":
+				inCaption = true
+				caption.Reset()
+			case "img":
+				src := resolve(baseURL, firstAttr(tok, "src", "data-src"))
+				if src == "" {
+					continue
+				}
+				m := feed.Media{
+					Url:      src,
+					Type:     feed.MediaImage,
+					MimeType: mimeType(src),
+					Caption:  firstAttr(tok, "alt", "title"),
+				}
+				m.Width, _ = strconv.Atoi(attr(tok, "width"))
+				m.Height, _ = strconv.Atoi(attr(tok, "height"))
+				result = append(result, m)
+			case "video", "audio", "source":
+				src := resolve(baseURL, attr(tok, "src"))
+				if src == "" {
+					continue
+				}
+				typ := attr(tok, "type")
+				if typ == "" {
+					typ = mimeType(src)
+				}
+				kind := feed.MediaType(typ, tok.Data)
+				if tok.Data == "source" {
+					kind = feed.MediaType(typ, feed.MediaVideo)
+				}
+				m := feed.Media{Url: src, Type: kind, MimeType: typ}
+				m.Width, _ = strconv.Atoi(attr(tok, "width"))
+				m.Height, _ = strconv.Atoi(attr(tok, "height"))
+				result = append(result, m)
+			case "iframe":
+				src := resolve(baseURL, attr(tok, "src"))
+				if src == "" || !isVideoHost(src) {
+					continue
+				}
+				m := feed.Media{Url: src, Type: feed.MediaVideo, Caption: attr(tok, "title")}
+				m.Width, _ = strconv.Atoi(attr(tok, "width"))
+				m.Height, _ = strconv.Atoi(attr(tok, "height"))
+				result = append(result, m)
+			case "a":
+				href := resolve(baseURL, attr(tok, "href"))
+				if href == "" || !documentExt[strings.ToLower(path.Ext(pathOf(href)))] {
+					continue
+				}
+				result = append(result, feed.Media{Url: href, Type: feed.MediaDocument, MimeType: mimeType(href), Caption: attr(tok, "title")})
+				if tt == html.StartTagToken {
+					link = len(result) - 1
+					linkText.Reset()
+				}
+			}
+
+		case html.EndTagToken:
+			switch tok.Data {
+			case "figcaption":
+				inCaption = false
+				// Подпись figure относится ко всем изображениям внутри него
+				if text := normalize(caption.String()); text != "" && figure >= 0 {
+					for i := figure; i < len(result); i++ {
+						result[i].Caption = text
+					}
+				}
+			case "figure":
+				figure = -1
+			case "a":
+				if link >= 0 && result[link].Caption == "" {
+					result[link].Caption = normalize(linkText.String())
+				}
+				link = -1
+			}
+
+		case html.TextToken:
+			if inCaption {
+				caption.WriteString(tok.Data)
+			}
+			if link >= 0 {
+				linkText.WriteString(tok.Data)
+			}
+		}
+	}
+
+	return feed.MergeMedia(result)
+}
+
+// attr возвращает значение атрибута key токена
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// firstAttr возвращает первое непустое значение из атрибутов keys
+func firstAttr(tok html.Token, keys ...string) string {
+	for _, key := range keys {
+		if v := attr(tok, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolve разрешает адрес относительно адреса записи, оставляет только http и https
+func resolve(base *url.URL, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil && base.IsAbs() {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// pathOf возвращает путь адреса без параметров
+func pathOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+// mimeType определяет MIME-тип по расширению файла
+func mimeType(rawURL string) string {
+	t := mime.TypeByExtension(strings.ToLower(path.Ext(pathOf(rawURL))))
+	if i := strings.Index(t, ";"); i > 0 {
+		t = t[:i]
+	}
+	return t
+}
+
+func isVideoHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	for _, h := range videoHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -9,6 +9,17 @@ type Metrics struct {
 	ErrorRequests    *prometheus.CounterVec
 	EntitiesInserted *prometheus.CounterVec
 	EntitiesUpdated  *prometheus.CounterVec
+	EntriesSkipped   *prometheus.CounterVec
+	BackfillPages    *prometheus.CounterVec
+	BackfillEntries  *prometheus.CounterVec
+	BackfillErrors   *prometheus.CounterVec
+	BackfillPage     *prometheus.GaugeVec
+	LanguageMismatch *prometheus.CounterVec
+	DuplicatesFound  *prometheus.CounterVec
+	AlertsMatched    *prometheus.CounterVec
+	AlertsDelivered  *prometheus.CounterVec
+	WebSubPushes     *prometheus.CounterVec
+	TelegramMessages *prometheus.CounterVec
 }
 
 func NewMetrics() *Metrics {
@@ -44,6 +55,91 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"url", "chunks"}, // Метка для URL, кол-во фрагментов
 		),
+		// Метрика для подсчета записей ленты, пропущенных без изменений с прошлого опроса
+		EntriesSkipped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_entries_skipped_total",
+				Help: "Total number of unchanged feed entries skipped before processing.",
+			},
+			[]string{"url"}, // Метка для URL ленты
+		),
+		// Метрики исторической индексации
+		BackfillPages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_backfill_pages_total",
+				Help: "Total number of backfill pages processed.",
+			},
+			[]string{"source", "key"}, // Метка для источника и ключа позиции
+		),
+		BackfillEntries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_backfill_entries_total",
+				Help: "Total number of backfill entries sent to processing.",
+			},
+			[]string{"source", "key"},
+		),
+		BackfillErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_backfill_errors_total",
+				Help: "Total number of failed backfill page requests.",
+			},
+			[]string{"source", "key"},
+		),
+		BackfillPage: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rss_parser_backfill_current_page",
+				Help: "Number of the last committed backfill page.",
+			},
+			[]string{"source", "key"},
+		),
+		// Метрика для подсчета записей, язык которых не совпадает с языком ленты
+		LanguageMismatch: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_language_mismatch_total",
+				Help: "Total number of entries whose detected language differs from the feed language.",
+			},
+			[]string{"resource_id", "lang", "detected"}, // Метки для ресурса, языка ленты и определенного языка
+		),
+		// Метрика для подсчета записей, которые почти полностью повторяют ранее полученные записи
+		DuplicatesFound: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_duplicates_found_total",
+				Help: "Total number of entries marked as near-duplicates of earlier entries.",
+			},
+			[]string{"resource_id"}, // Метка для ресурса записи-копии
+		),
+		// Метрика для подсчета записей, подошедших под сохраненные запросы оповещений
+		AlertsMatched: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_alerts_matched_total",
+				Help: "Total number of entries matched by saved alert queries.",
+			},
+			[]string{"resource_id"},
+		),
+		// Метрика для подсчета доставленных и недоставленных оповещений
+		AlertsDelivered: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_alerts_delivered_total",
+				Help: "Total number of alert webhook deliveries.",
+			},
+			[]string{"webhook", "status"}, // Метки для имени вебхука и результата: ok или failed
+		),
+		// Метрика для подсчета обновлений лент, присланных хабами WebSub
+		WebSubPushes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_websub_pushes_total",
+				Help: "Total number of feed updates pushed by WebSub hubs.",
+			},
+			[]string{"url"}, // Метка для URL ленты
+		),
+		// Метрика для подсчета сообщений, отправленных в Telegram
+		TelegramMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_parser_telegram_messages_total",
+				Help: "Total number of entries posted to Telegram chats.",
+			},
+			[]string{"chat", "status"}, // Метки для чата и результата: ok или failed
+		),
 	}
 }
 
@@ -52,4 +148,15 @@ func (m *Metrics) Register() {
 	prometheus.MustRegister(m.ErrorRequests)
 	prometheus.MustRegister(m.EntitiesInserted)
 	prometheus.MustRegister(m.EntitiesUpdated)
+	prometheus.MustRegister(m.EntriesSkipped)
+	prometheus.MustRegister(m.BackfillPages)
+	prometheus.MustRegister(m.BackfillEntries)
+	prometheus.MustRegister(m.BackfillErrors)
+	prometheus.MustRegister(m.BackfillPage)
+	prometheus.MustRegister(m.LanguageMismatch)
+	prometheus.MustRegister(m.DuplicatesFound)
+	prometheus.MustRegister(m.AlertsMatched)
+	prometheus.MustRegister(m.AlertsDelivered)
+	prometheus.MustRegister(m.WebSubPushes)
+	prometheus.MustRegister(m.TelegramMessages)
 }
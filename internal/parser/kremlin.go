@@ -2,22 +2,63 @@ package parser
 
 import (
 	"fmt"
-	"github.com/terratensor/feed-parser/internal/entities/feed"
-	"golang.org/x/net/html"
+	"log"
+	"os"
 	"regexp"
 	"time"
+
+	"github.com/terratensor/feed-parser/internal/backfill"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/htmlnode"
+	"github.com/terratensor/feed-parser/internal/lib/logger/sl"
+	"golang.org/x/net/html"
 )
 
+func (p *Parser) parseKremlin(url string) []feed.Entry {
+	page, err := p.fetchKremlinPage(url)
+	if err != nil {
+		return nil
+	}
+	return page.Entries
+}
+
+// fetchKremlinPage получает страницу Atom ленты kremlin.ru,
+// адрес следующей страницы берется из ссылки next объекта мета
+func (p *Parser) fetchKremlinPage(url string) (*backfill.Page, error) {
+
+	node, err := htmlnode.GetTopicBody(url, p.Link.UserAgent)
+	if os.IsTimeout(err) {
+		// Увеличиваем счетчик ошибок
+		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
+		log.Printf("server timeout error %v", err)
+		return nil, err
+	}
+	if err != nil {
+		// Увеличиваем счетчик ошибок
+		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
+		log.Printf("failed to decode request body %v", sl.Err(err))
+		return nil, err
+	}
+
+	// Увеличиваем счетчик успешных запросов
+	p.metrics.SuccessRequests.WithLabelValues(p.Link.Url, "0").Inc()
+
+	// Если ссылка next пустая, достигнут конец ленты
+	return &backfill.Page{
+		Entries: p.parseEntries(node),
+		Next:    parseMeta(node).Next,
+	}, nil
+}
+
 func (p *Parser) parseEntries(n *html.Node) []feed.Entry {
 
 	var entries []feed.Entry
 	var f func(*html.Node)
 
-	e := feed.Entry{}
-
 	f = func(n *html.Node) {
 
 		if n.Type == html.ElementNode && n.Data == "entry" {
+			e := feed.Entry{}
 			for cl := n.FirstChild; cl != nil; cl = cl.NextSibling {
 
 				if cl.Type == html.ElementNode && cl.Data == "title" {
@@ -52,7 +93,7 @@ func (p *Parser) parseEntries(n *html.Node) []feed.Entry {
 				}
 
 				if cl.Type == html.ElementNode && cl.Data == "content" {
-					e.Content = deleteImgTag(getInnerText(cl))
+					e.Content = getInnerText(cl)
 				}
 
 			}
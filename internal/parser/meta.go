@@ -1,9 +1,10 @@
-package kremlin
+package parser
 
 import (
-	"fmt"
-	"golang.org/x/net/html"
+	"log"
 	"time"
+
+	"golang.org/x/net/html"
 )
 
 type Meta struct {
@@ -16,22 +17,13 @@ type Meta struct {
 	Last    string     `json:"last"`
 }
 
-// NewMeta инициализирует и возвращает новый объект Meta.
+// parseMeta парсит данный html.Node для извлечения мета-информации.
 //
-// Он не принимает параметров и возвращает указатель на объект Meta.
 // Это объект, который содержит навигационную информацию о страницах ленты.
 // Время последнего обновления страницы ленты.
 // Адрес текущей страницы, адрес следующей станицы, адрес предыдущей и адрес последней и первой страницы в ленте.
 // С помощью этой информации можно совершать обход ленты.
-func NewMeta() *Meta {
-	meta := Meta{}
-	return &meta
-}
-
-// parseMeta парсит данный html.Node для извлечения мета-информации.
-//
-// Принимает указатель на html.Node в качестве параметра и возвращает указатель на структуру Meta.
-func (i *Indexer) parseMeta(node *html.Node) {
+func parseMeta(node *html.Node) *Meta {
 	var f func(*html.Node)
 
 	meta := Meta{}
@@ -44,7 +36,7 @@ func (i *Indexer) parseMeta(node *html.Node) {
 			if node.Type == html.ElementNode && node.Data == "updated" {
 				t, err := time.Parse("2006-01-02T15:04:05-07:00", getInnerText(node))
 				if err != nil {
-					fmt.Println(err)
+					log.Printf("cannot parse feed updated date: %v", err)
 					return
 				}
 				meta.Updated = &t
@@ -78,5 +70,5 @@ func (i *Indexer) parseMeta(node *html.Node) {
 	}
 	f(node)
 
-	i.Meta = &meta
+	return &meta
 }
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/mmcdole/gofeed"
+	"github.com/terratensor/feed-parser/internal/backfill"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// fetchMidPage получает страницу HTML списка новостей mid.ru,
+// следующая страница задается параметром PAGEN_1.
+// Со страницы списка берутся адрес, заголовок и дата публикации записи, анонс — со страницы записи,
+// и запись собирается той же функцией feed.MakeEntries, что и записи RSS ленты.
+// Контент записей заполняется краулером при обработке задачи, так же как для записей RSS ленты.
+func (p *Parser) fetchMidPage(rawURL string) (*backfill.Page, error) {
+	items, err := p.parseAnnounceItems(rawURL)
+	if err == nil {
+		err = p.fillMidDescriptions(items)
+	}
+	if err != nil {
+		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
+		return nil, err
+	}
+	p.metrics.SuccessRequests.WithLabelValues(p.Link.Url, "0").Inc()
+
+	// На пустой странице список новостей закончился
+	if len(items) == 0 {
+		return &backfill.Page{}, nil
+	}
+
+	next, err := nextMidPageUrl(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backfill.Page{Entries: feed.MakeEntries(items, p.Link), Next: next}, nil
+}
+
+// parseAnnounceItems возвращает записи страницы списка новостей в виде элементов RSS ленты
+func (p *Parser) parseAnnounceItems(rawURL string) ([]*gofeed.Item, error) {
+
+	var items []*gofeed.Item
+
+	c := p.midCollector()
+
+	// Даты на сайте МИД указаны по московскому времени
+	loc, err := time.LoadLocation("Etc/GMT-3")
+	if err != nil {
+		return nil, fmt.Errorf("cannot load location: %v", err)
+	}
+
+	c.OnHTML("ul.announce.announce_articles", func(e *colly.HTMLElement) {
+
+		e.ForEach("li", func(_ int, e *colly.HTMLElement) {
+			if e.Attr("class") == "announce__item" {
+				item := &gofeed.Item{}
+
+				// populate date
+				adate := e.ChildText("span.announce__date")
+				atime := e.ChildText("span.announce__time")
+
+				datetime := fmt.Sprintf("%s %s", adate, atime)
+
+				date, err := time.ParseInLocation("02.01.2006 15:04", datetime, loc)
+				if err != nil {
+					date, err = time.ParseInLocation("2 January 2006 15:04", datetime, loc)
+					if err != nil {
+						log.Printf("cannot parse date: %v", err)
+						date = time.Time{}
+					}
+				}
+
+				item.PublishedParsed = &date
+
+				// populate url, ссылка приводится к виду ссылок RSS ленты
+				urlValue := e.ChildAttr("a", "href")
+				var u = url.URL{
+					Scheme: "https",
+					Host:   "mid.ru",
+					Path:   urlValue,
+				}
+				item.Link = u.String()
+
+				// populate title
+				item.Title = e.ChildText("a")
+
+				// append item
+				items = append(items, item)
+			}
+		})
+	})
+
+	p.midPause()
+
+	err = c.Visit(rawURL)
+	if err != nil {
+		log.Printf("Crawler Error: %v", err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// fillMidDescriptions заполняет анонсы записей списка новостей описанием со страниц записей,
+// которое RSS лента mid.ru передает в поле description
+func (p *Parser) fillMidDescriptions(items []*gofeed.Item) error {
+	for _, item := range items {
+		var description, ogDescription string
+
+		c := p.midCollector()
+		c.OnHTML(`meta[name="description"]`, func(e *colly.HTMLElement) {
+			description = strings.TrimSpace(e.Attr("content"))
+		})
+		c.OnHTML(`meta[property="og:description"]`, func(e *colly.HTMLElement) {
+			ogDescription = strings.TrimSpace(e.Attr("content"))
+		})
+
+		p.midPause()
+
+		if err := c.Visit(item.Link); err != nil {
+			return fmt.Errorf("failed to fetch %v: %v", item.Link, err)
+		}
+		if description == "" {
+			description = ogDescription
+		}
+		item.Description = description
+	}
+	return nil
+}
+
+// midCollector создает коллектор для страниц mid.ru с User-Agent ленты
+func (p *Parser) midCollector() *colly.Collector {
+	c := colly.NewCollector()
+
+	if p.Link.UserAgent != "" {
+		c.UserAgent = p.Link.UserAgent
+	} else {
+		c.UserAgent = "PostmanRuntime/7.37.0"
+	}
+	return c
+}
+
+// midPause выдерживает паузу 2-3 секунды перед запросом к mid.ru
+func (p *Parser) midPause() {
+	n := 2 + rand.Intn(2)
+	d := time.Duration(n)
+	time.Sleep(d * time.Second)
+}
+
+// midStartUrl возвращает адрес первой страницы списка новостей mid.ru для ленты rawURL.
+// Для ленты RSS, например https://mid.ru/ru/rss.php, это список новостей раздела на том же языке
+// https://mid.ru/ru/foreign_policy/news/?PAGEN_1=1, адрес списка новостей используется как есть.
+func midStartUrl(rawURL string) (string, error) {
+	newUrl, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse url: %v", err)
+	}
+
+	if strings.HasSuffix(newUrl.Path, "/rss.php") {
+		newUrl.Path = strings.TrimSuffix(newUrl.Path, "rss.php") + "foreign_policy/news/"
+		newUrl.RawQuery = ""
+	}
+	values := newUrl.Query()
+	if values.Get("PAGEN_1") == "" {
+		values.Set("PAGEN_1", "1")
+		newUrl.RawQuery = values.Encode()
+	}
+
+	return newUrl.String(), nil
+}
+
+// nextMidPageUrl увеличивает номер страницы в параметре PAGEN_1,
+// адрес без параметра считается первой страницей
+func nextMidPageUrl(rawURL string) (string, error) {
+	newUrl, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse url: %v", err)
+	}
+
+	values := newUrl.Query()
+	num := 1
+	if v := values.Get("PAGEN_1"); v != "" {
+		num, err = strconv.Atoi(v)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse url PAGEN_1 param: %v", err)
+		}
+	}
+	values.Set("PAGEN_1", strconv.Itoa(num+1))
+	newUrl.RawQuery = values.Encode()
+
+	return newUrl.String(), nil
+}
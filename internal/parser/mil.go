@@ -1,19 +1,17 @@
 package parser
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"net/http"
-	"regexp"
-	"strings"
 	"time"
 
+	"github.com/terratensor/feed-parser/internal/backfill"
 	"github.com/terratensor/feed-parser/internal/entities/feed"
-	"golang.org/x/net/html"
 )
 
 type ResponseData struct {
@@ -27,75 +25,80 @@ type ResponseData struct {
 	} `json:"data"`
 }
 
-// cleanHTML обрабатывает HTML, оставляя только содержимое тегов p (без атрибутов и вложенных тегов)
-func cleanHTML(input string) string {
-	var buf bytes.Buffer
-	tokenizer := html.NewTokenizer(strings.NewReader(input))
-
-	for {
-		tt := tokenizer.Next()
-		switch tt {
-		case html.ErrorToken:
-			return buf.String()
-
-		case html.StartTagToken, html.SelfClosingTagToken:
-			tagName, _ := tokenizer.TagName()
-			if string(tagName) == "p" {
-				// Начало <p>, начинаем читать содержимое
-				var pContent strings.Builder
+// parseMil читает первую страницу JSON API mil.ru. Если для ленты задана пагинация,
+// то читает следующие страницы, пока на странице не встретится уже сохраненная запись,
+// записи страницы не станут старше pagination.max_age или не будет прочитано pagination.max_pages страниц.
+func (p *Parser) parseMil(url string) []feed.Entry {
+	var entries []feed.Entry
 
-				readContent(tokenizer, &pContent)
+	maxPages := 1
+	if p.pagination.Enabled() && p.pagination.MaxPages > 0 {
+		maxPages = p.pagination.MaxPages
+	}
 
-				// Очищаем текст от лишних пробелов и переносов
-				cleanText := normalizeWhitespace(pContent.String())
+	var cutoff time.Time
+	if p.pagination.MaxAge != nil {
+		cutoff = time.Now().Add(-*p.pagination.MaxAge)
+	}
 
-				// Пишем результат как простой <p> с очищенным текстом
-				buf.WriteString("<p>")
-				buf.WriteString(cleanText)
-				buf.WriteString("</p>\n")
-			}
+	for n := 1; n <= maxPages && url != ""; n++ {
+		page, err := p.fetchMilPage(url)
+		if err != nil {
+			break
+		}
+		entries = append(entries, page.Entries...)
 
-		// Игнорируем остальные токены
-		case html.TextToken, html.EndTagToken, html.CommentToken, html.DoctypeToken:
-			// Пропускаем
+		if n == maxPages || page.Next == "" {
+			break
+		}
+		if p.hasKnownEntry(page.Entries) {
+			break
+		}
+		if !cutoff.IsZero() && olderThan(page.Entries, cutoff) {
+			log.Printf("mil: entries on page %d are older than %v, stop", n, cutoff.Format(time.DateOnly))
+			break
 		}
+
+		log.Printf("mil: no known entries on page %d, following next page %v", n, page.Next)
+		url = page.Next
 	}
+
+	return entries
 }
 
-// readContent рекурсивно считывает текстовое содержимое до закрывающего </p>
-func readContent(tokenizer *html.Tokenizer, builder *strings.Builder) {
-Loop:
-	for {
-		tt := tokenizer.Next()
-		switch tt {
-		case html.TextToken:
-			text := tokenizer.Text()
-			builder.Write(text)
-		case html.EndTagToken:
-			tagName, _ := tokenizer.TagName()
-			if string(tagName) == "p" {
-				break Loop
-			}
-		case html.StartTagToken, html.SelfClosingTagToken:
-			// Пропускаем любой вложенный тег — просто переходим к следующему токену
-			continue
-		case html.ErrorToken:
-			break Loop
+// hasKnownEntry сообщает, есть ли среди записей уже сохраненная в хранилище.
+// Если хранилище не задано, считается, что сохраненная запись найдена.
+func (p *Parser) hasKnownEntry(entries []feed.Entry) bool {
+	if p.storage == nil {
+		return true
+	}
+	for _, e := range entries {
+		dbe, err := p.storage.Storage.FindAllByUrl(context.Background(), e.Url)
+		if err != nil {
+			log.Printf("failed to find entry by url %v: %v", e.Url, err)
+			return true
+		}
+		if len(dbe) > 0 {
+			return true
 		}
 	}
+	return false
 }
 
-// normalizeWhitespace убирает лишние пробелы и переносы строк
-func normalizeWhitespace(s string) string {
-	// Заменяем любые пробельные символы на одиночный пробел
-	space := regexp.MustCompile(`\s+`)
-	s = space.ReplaceAllString(s, " ")
-
-	// Обрезаем пробелы по краям
-	return strings.TrimSpace(s)
+// olderThan сообщает, что все записи с датой публикации опубликованы раньше t
+func olderThan(entries []feed.Entry, t time.Time) bool {
+	for _, e := range entries {
+		if e.Published == nil || !e.Published.Before(t) {
+			return false
+		}
+	}
+	return len(entries) > 0
 }
 
-func (p *Parser) parseMil(url string) []feed.Entry {
+// fetchMilPage получает страницу JSON API mil.ru.
+// Если для ленты задана пагинация, в Next возвращается адрес следующей страницы,
+// пустая страница считается последней.
+func (p *Parser) fetchMilPage(url string) (*backfill.Page, error) {
 	var resp *http.Response
 	var body []byte
 	var err error
@@ -151,7 +154,7 @@ func (p *Parser) parseMil(url string) []feed.Entry {
 
 	// После 10 неудачных попыток resp может быть nil
 	if body == nil {
-		return nil
+		return nil, fmt.Errorf("failed to fetch %v after 10 attempts", url)
 	}
 
 	var response ResponseData
@@ -159,7 +162,7 @@ func (p *Parser) parseMil(url string) []feed.Entry {
 	if err != nil {
 		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
 		log.Printf("failed to unmarshal JSON: %v", err)
-		return nil
+		return nil, err
 	}
 
 	var entries []feed.Entry
@@ -176,12 +179,11 @@ func (p *Parser) parseMil(url string) []feed.Entry {
 		}
 
 		author := "Министерство обороны Российской Федерации"
-		cleanedContent := cleanHTML(item.Text)
 
 		entry := feed.Entry{
 			Title:      item.Title,
 			Url:        fmt.Sprintf("https://mil.ru/news/%s", item.ID),
-			Content:    cleanedContent,
+			Content:    item.Text,
 			Summary:    item.Preview,
 			Published:  publishedTime,
 			Language:   p.Link.Lang,
@@ -194,5 +196,13 @@ func (p *Parser) parseMil(url string) []feed.Entry {
 
 	// Увеличиваем счетчик успешных запросов
 	p.metrics.SuccessRequests.WithLabelValues(p.Link.Url, "0").Inc()
-	return entries
+
+	page := &backfill.Page{Entries: entries}
+	if p.pagination.Enabled() && len(entries) > 0 {
+		page.Next, err = nextPageUrl(p.pagination, url, len(entries))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
 }
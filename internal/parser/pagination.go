@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/terratensor/feed-parser/internal/config"
+)
+
+// nextPageUrl возвращает адрес следующей страницы API: увеличивает номер страницы
+// в параметре page_param на 1 и смещение в параметре offset_param на размер страницы.
+// Если параметра нет в адресе, то считается, что номер страницы равен 1, а смещение 0.
+func nextPageUrl(pagination config.Pagination, rawURL string, count int) (string, error) {
+	newUrl, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse url: %v", err)
+	}
+
+	values := newUrl.Query()
+
+	if pagination.PageParam != "" {
+		page, err := intParam(values, pagination.PageParam, 1)
+		if err != nil {
+			return "", err
+		}
+		values.Set(pagination.PageParam, strconv.Itoa(page+1))
+	}
+
+	if pagination.OffsetParam != "" {
+		offset, err := intParam(values, pagination.OffsetParam, 0)
+		if err != nil {
+			return "", err
+		}
+		size := pagination.PageSize
+		if size <= 0 {
+			size = count
+		}
+		values.Set(pagination.OffsetParam, strconv.Itoa(offset+size))
+	}
+
+	newUrl.RawQuery = values.Encode()
+	return newUrl.String(), nil
+}
+
+func intParam(values url.Values, name string, def int) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse url %v param: %v", name, err)
+	}
+	return n, nil
+}
@@ -5,17 +5,17 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
-	"os"
 	"sync"
 	"time"
 
 	"github.com/mmcdole/gofeed"
 	"github.com/terratensor/feed-parser/internal/config"
 	"github.com/terratensor/feed-parser/internal/entities/feed"
-	"github.com/terratensor/feed-parser/internal/htmlnode"
-	"github.com/terratensor/feed-parser/internal/lib/logger/sl"
 	"github.com/terratensor/feed-parser/internal/metrics"
 	"github.com/terratensor/feed-parser/internal/model/link"
+	"github.com/terratensor/feed-parser/internal/splitter"
+	"github.com/terratensor/feed-parser/internal/watermark"
+	"github.com/terratensor/feed-parser/internal/websub"
 )
 
 type Parser struct {
@@ -23,6 +23,13 @@ type Parser struct {
 	Delay       time.Duration
 	RandomDelay time.Duration
 	metrics     *metrics.Metrics
+	pagination  config.Pagination
+	storage     *feed.Entries
+	watermark   *watermark.Filter
+	websub      *websub.Subscriber
+	chunker     splitter.Chunker // Разбивка записей ленты на фрагменты
+	hub         string           // Хаб WebSub, объявленный в ленте
+	topic       string           // Адрес ленты rel="self" для подписки через хаб
 }
 
 // NewParser creates a new Parser instance with configuration from both main config and parser-specific config.
@@ -55,11 +62,34 @@ func NewParser(cfg config.Parser, mainCfg config.Config, metrics *metrics.Metric
 		Delay:       delay,
 		RandomDelay: randomDelay,
 		metrics:     metrics,
+		pagination:  cfg.Pagination,
 	}
 	return np
 }
 
-func (p *Parser) Run(ch chan feed.Entry, fp *gofeed.Parser, wg *sync.WaitGroup) {
+// WithStorage задает хранилище, по которому парсер определяет уже сохраненные записи
+// при чтении следующих страниц ленты
+func (p *Parser) WithStorage(storage *feed.Entries) *Parser {
+	p.storage = storage
+	return p
+}
+
+// Entry запись ленты, переданная парсером на обработку, и разбивка на фрагменты, заданная для ленты.
+// После обработки записи вызывается Done, чтобы парсер отметил сохраненную запись в состоянии опроса ленты.
+type Entry struct {
+	feed.Entry
+	Chunker splitter.Chunker
+	done    func(err error)
+}
+
+// Done сообщает парсеру результат обработки записи, err — ошибка, из-за которой запись не сохранена
+func (e Entry) Done(err error) {
+	if e.done != nil {
+		e.done(err)
+	}
+}
+
+func (p *Parser) Run(ch chan Entry, fp *gofeed.Parser, wg *sync.WaitGroup) {
 
 	log.Printf("🚩 run parser: delay: %v, random delay: %v, url: %v", p.Delay, p.RandomDelay, p.Link.Url)
 
@@ -76,10 +106,14 @@ func (p *Parser) Run(ch chan feed.Entry, fp *gofeed.Parser, wg *sync.WaitGroup)
 		entries := p.getEntries(fp)
 		log.Printf("fetched the contents of a given url %v", p.Link.Url)
 
+		entries = p.skipKnown(entries)
+
 		for _, entry := range entries {
-			ch <- entry
+			ch <- p.entry(entry)
 		}
 
+		p.subscribe(ch)
+
 		// Ожидаем установленное время до следующией итерации парсинга
 		randomDelay := time.Duration(0)
 		if p.RandomDelay != 0 {
@@ -89,6 +123,60 @@ func (p *Parser) Run(ch chan feed.Entry, fp *gofeed.Parser, wg *sync.WaitGroup)
 	}
 }
 
+// WithChunker задает разбивку на фрагменты, с которой обрабатываются записи ленты
+func (p *Parser) WithChunker(chunker splitter.Chunker) *Parser {
+	p.chunker = chunker
+	return p
+}
+
+// WithWatermark задает фильтр, который пропускает записи, не изменившиеся с прошлого опроса ленты
+func (p *Parser) WithWatermark(filter *watermark.Filter) *Parser {
+	p.watermark = filter
+	return p
+}
+
+// WithWebSub задает подписчика WebSub. Если в ленте объявлен хаб, парсер подписывается на обновления
+// и сразу отправляет присланные хабом записи на обработку, опрос ленты при этом продолжается.
+func (p *Parser) WithWebSub(s *websub.Subscriber) *Parser {
+	p.websub = s
+	return p
+}
+
+// subscribe подписывается на обновления ленты или продлевает подписку
+func (p *Parser) subscribe(ch chan Entry) {
+	if p.websub == nil || p.hub == "" {
+		return
+	}
+	if err := p.websub.Ensure(p.hub, p.topic, p.pushHandler(ch)); err != nil {
+		log.Printf("websub: %v", err)
+	}
+}
+
+// pushHandler разбирает присланное хабом содержимое ленты и отправляет записи на обработку
+func (p *Parser) pushHandler(ch chan Entry) websub.PushHandler {
+	return func(topic string, body []byte) {
+		fp := gofeed.NewParser()
+		fp.AtomTranslator = &websub.AtomTranslator{}
+		gf, err := fp.ParseString(string(body))
+		if err != nil {
+			log.Printf("websub: failed to parse content pushed for %v: %v", topic, err)
+			return
+		}
+
+		entries := feed.MakeEntries(gf.Items, p.Link)
+		p.metrics.WebSubPushes.WithLabelValues(p.Link.Url).Inc()
+		log.Printf("websub: %d entries pushed for %v", len(entries), p.Link.Url)
+		for _, entry := range entries {
+			ch <- Entry{Entry: entry, Chunker: p.chunker}
+		}
+	}
+}
+
+// Fetch однократно получает и разбирает ленту, не отправляя записи в канал
+func (p *Parser) Fetch(fp *gofeed.Parser) []feed.Entry {
+	return p.getEntries(fp)
+}
+
 // Получает записи ленты, если сайт kremlin,
 // то запускает kremlin парсер,
 // иначе запускает gofeed.Parser
@@ -125,27 +213,47 @@ func (p *Parser) getEntries(fp *gofeed.Parser) []feed.Entry {
 			return nil
 		}
 		entries = append(entries, feed.MakeEntries(gf.Items, p.Link)...)
+
+		p.hub, p.topic = websub.Discover(gf)
+		if p.topic == "" {
+			p.topic = p.Link.Url
+		}
 	}
 	return entries
 }
 
-func (p *Parser) parseKremlin(url string) []feed.Entry {
+// entry передает запись ленты на обработку, после сохранения запись отмечается в состоянии опроса ленты
+func (p *Parser) entry(e feed.Entry) Entry {
+	if p.watermark == nil {
+		return Entry{Entry: e, Chunker: p.chunker}
+	}
+
+	key := watermark.Key(p.Link.ResourceID, p.Link.Lang, p.Link.Url)
+	return Entry{Entry: e, Chunker: p.chunker, done: func(err error) {
+		if err != nil {
+			return
+		}
+		if err := p.watermark.Commit(key, e); err != nil {
+			log.Printf("watermark error %v: %v", p.Link.Url, err)
+		}
+	}}
+}
 
-	node, err := htmlnode.GetTopicBody(url, p.Link.UserAgent)
-	if os.IsTimeout(err) {
-		// Увеличиваем счетчик ошибок
-		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
-		log.Printf("server timeout error %v", err)
-		return nil
+// skipKnown отбрасывает записи, не изменившиеся с прошлого опроса ленты,
+// чтобы они не попадали в очередь задач и не вызывали лишних запросов к мантикоре
+func (p *Parser) skipKnown(entries []feed.Entry) []feed.Entry {
+	if p.watermark == nil || len(entries) == 0 {
+		return entries
 	}
+
+	key := watermark.Key(p.Link.ResourceID, p.Link.Lang, p.Link.Url)
+	result, skipped, err := p.watermark.Apply(key, entries)
 	if err != nil {
-		// Увеличиваем счетчик ошибок
-		p.metrics.ErrorRequests.WithLabelValues(p.Link.Url, err.Error(), "0").Inc()
-		log.Printf("failed to decode request body %v", sl.Err(err))
-		return nil
+		log.Printf("watermark error %v: %v", p.Link.Url, err)
 	}
-
-	// Увеличиваем счетчик успешных запросов
-	p.metrics.SuccessRequests.WithLabelValues(p.Link.Url, "0").Inc()
-	return p.parseEntries(node)
+	if skipped > 0 {
+		p.metrics.EntriesSkipped.WithLabelValues(p.Link.Url).Add(float64(skipped))
+		log.Printf("skipped %d unchanged entries of %v", skipped, p.Link.Url)
+	}
+	return result
 }
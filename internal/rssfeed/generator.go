@@ -0,0 +1,344 @@
+package rssfeed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/sentence"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+	"github.com/terratensor/feed-parser/internal/tagger"
+	"github.com/terratensor/feed-parser/internal/websub"
+)
+
+var authorMap = map[int]string{
+	1: "Президент Российской Федерации",
+	2: "Министерство иностранных дел Российской Федерации",
+	3: "Министерство обороны Российской Федерации",
+}
+
+// DefaultBaseURL адрес, по которому публикуются фиды
+const DefaultBaseURL = "https://rss.feed.svodd.ru"
+
+// Generator создает RSS-фиды из записей, опубликованных за последние duration,
+// и сохраняет их в каталог dir
+type Generator struct {
+	entries  *feed.Entries
+	duration time.Duration
+	dir      string
+	tags     []tagger.Entity
+	baseURL  string
+	hubs     []string
+	client   *http.Client
+	hashes   map[string][sha256.Size]byte // Хеши содержимого сохраненных фидов по имени файла
+}
+
+func NewGenerator(entries *feed.Entries, duration time.Duration, dir string) *Generator {
+	return &Generator{
+		entries:  entries,
+		duration: duration,
+		dir:      dir,
+		baseURL:  DefaultBaseURL,
+		hashes:   make(map[string][sha256.Size]byte),
+	}
+}
+
+// WithWebSub задает адрес публикации фидов baseURL и хабы WebSub, которым сообщается
+// об изменении фидов, пустой baseURL — адрес по умолчанию
+func (g *Generator) WithWebSub(hubs []string, baseURL string) *Generator {
+	if baseURL != "" {
+		g.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	g.hubs = hubs
+	g.client = &http.Client{Timeout: 10 * time.Second}
+	return g
+}
+
+// WithTagFeeds включает формирование фидов tags/<slug>.xml для сущностей словаря с признаком feed
+func (g *Generator) WithTagFeeds(entities []tagger.Entity) *Generator {
+	for _, e := range entities {
+		if e.Feed {
+			g.tags = append(g.tags, e)
+		}
+	}
+	return g
+}
+
+// Generate формирует основной фид и фиды ресурсов и записывает их в файлы.
+// Возвращает количество записей в основном фиде.
+func (g *Generator) Generate(ctx context.Context) (int, error) {
+
+	limit := g.entries.Storage.CalculateLimitCount(g.duration)
+	ch, err := g.entries.Find(ctx, g.duration)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find all entries: %v", err)
+	}
+
+	// Основной фид, содержащий все новости
+	svoddFeed := &RssFeed{
+		Title:       "Поиск по сайтам Кремля, МИД и Минобороны",
+		Link:        "https://feed.svodd.ru",
+		Description: "Поиск по сайтам Президента России, Министерства иностранных дел Российской Федерации, Министерство обороны Российской Федерации",
+	}
+
+	// Фид для Кремля (ResourceID = 1)
+	kremlinFeed := &RssFeed{
+		Title:       "Новости Кремля",
+		Link:        "https://rss.feed.svodd.ru/kremlin.xml",
+		Description: "Новости с сайта Президента Российской Федерации",
+	}
+
+	// Фид для МИД (ResourceID = 2)
+	midFeed := &RssFeed{
+		Title:       "Новости МИД",
+		Link:        "https://rss.feed.svodd.ru/mid.xml",
+		Description: "Новости с сайта Министерства иностранных дел Российской Федерации",
+	}
+
+	// Фид для Минобороны (ResourceID = 3)
+	milFeed := &RssFeed{
+		Title:       "Новости Минобороны",
+		Link:        "https://rss.feed.svodd.ru/mil.xml",
+		Description: "Новости с сайта Министерства обороны Российской Федерации",
+	}
+
+	// Фиды записей, в которых упомянуты сущности словаря
+	tagFeeds := make(map[int64]*RssFeed, len(g.tags))
+	for _, t := range g.tags {
+		tagFeeds[t.ID] = &RssFeed{
+			Title:       "Упоминания: " + t.Name,
+			Link:        "https://rss.feed.svodd.ru/tags/" + t.Slug + ".xml",
+			Description: "Новости с сайтов Кремля, МИД и Минобороны, в которых упоминается " + t.Name,
+		}
+	}
+
+	limitCount := 0
+	itemCount := 0
+loop:
+	for limitCount < limit {
+		select {
+		case <-ctx.Done():
+			return itemCount, ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				break loop
+			}
+
+			limitCount++
+
+			if utf8.RuneCountInString(e.Title) > 200 {
+				continue
+			}
+
+			// Пропускаем почти полные копии записей других источников
+			if e.DuplicateOf != "" {
+				continue
+			}
+
+			// Пропускаем все записи, опубликованные на языке отличном от русского
+			if e.Language != "ru" && e.Language != "" {
+				continue
+			}
+
+			item := makeItem(e)
+			item.Alternates = g.makeAlternates(ctx, e)
+
+			// Добавляем новость в основной фид
+			svoddFeed.Add(item)
+
+			// Добавляем новость в соответствующий фид на основе ResourceID
+			switch e.ResourceID {
+			case 1:
+				kremlinFeed.Add(item)
+			case 2:
+				midFeed.Add(item)
+			case 3:
+				milFeed.Add(item)
+			}
+
+			for _, id := range e.Tags {
+				if f, ok := tagFeeds[id]; ok {
+					f.Add(item)
+				}
+			}
+
+			itemCount++
+		}
+	}
+
+	// Сохраняем все фиды в файлы
+	g.saveFeedToFile(svoddFeed, "rss.xml")
+	g.saveFeedToFile(kremlinFeed, "kremlin.xml")
+	g.saveFeedToFile(midFeed, "mid.xml")
+	g.saveFeedToFile(milFeed, "mil.xml")
+
+	if len(g.tags) > 0 {
+		if err := os.MkdirAll(filepath.Join(g.dir, "tags"), 0755); err != nil {
+			return itemCount, fmt.Errorf("failed to create tags directory: %v", err)
+		}
+		for _, t := range g.tags {
+			g.saveFeedToFile(tagFeeds[t.ID], filepath.Join("tags", t.Slug+".xml"))
+		}
+	}
+
+	return itemCount, nil
+}
+
+// makeItem создает элемент RSS из записи
+func makeItem(e feed.Entry) *RssItem {
+	item := &RssItem{
+		Title:       e.Title,
+		Link:        makeEntryUrl(e.Url, e.Language),
+		PubDate:     AnyTimeFormat(time.RFC1123Z, *e.Published),
+		Author:      populateAuthorField(e.Author, e.ResourceID),
+		Content:     e.Content,
+		Description: populateDescription(e),
+	}
+
+	// Если есть URL источника новости, добавляем его в <source>
+	if e.Url != "" {
+		sourceName := "Оригинальный источник" // Значение по умолчанию
+		if name, ok := authorMap[e.ResourceID]; ok {
+			sourceName = name // Используем значение из authorMap
+		}
+		item.Source = &RssSource{
+			URL:  e.Url,      // URL источника новости
+			Name: sourceName, // Название источника из authorMap
+		}
+	}
+
+	item.Enclosure, item.Media = makeMedia(e.Media)
+
+	return item
+}
+
+// makeAlternates создает ссылки на переводы записи на другие языки
+func (g *Generator) makeAlternates(ctx context.Context, e feed.Entry) []*RssAlternate {
+	translations, err := g.entries.Translations(ctx, e)
+	if err != nil {
+		log.Printf("failed to find translations of %v: %v", e.Url, err)
+		return nil
+	}
+
+	var alternates []*RssAlternate
+	for _, t := range translations {
+		alternates = append(alternates, &RssAlternate{
+			Rel:      "alternate",
+			Hreflang: t.Language,
+			Href:     makeEntryUrl(t.Url, t.Language),
+			Title:    t.Title,
+		})
+	}
+	return alternates
+}
+
+// makeMedia создает media:content для всех медиа записи и enclosure для первого из них
+func makeMedia(media []feed.Media) (*RssEnclosure, []*RssMediaContent) {
+	var enclosure *RssEnclosure
+	var contents []*RssMediaContent
+
+	for _, m := range media {
+		contents = append(contents, &RssMediaContent{
+			URL:         m.Url,
+			Type:        m.MimeType,
+			Medium:      m.Type,
+			Width:       m.Width,
+			Height:      m.Height,
+			Description: m.Caption,
+		})
+
+		// enclosure требует MIME-тип, встроенные плееры без типа пропускаем
+		if enclosure == nil && m.MimeType != "" {
+			enclosure = &RssEnclosure{URL: m.Url, Length: "0", Type: m.MimeType}
+		}
+	}
+
+	return enclosure, contents
+}
+
+// saveFeedToFile сохраняет RSS-фид в файл, если его содержимое изменилось, и сообщает об изменении хабам WebSub
+func (g *Generator) saveFeedToFile(feed *RssFeed, name string) {
+	self := g.baseURL + "/" + filepath.ToSlash(name)
+	feed.AtomLinks = []*RssAlternate{{Rel: "self", Href: self}}
+	for _, hub := range g.hubs {
+		feed.AtomLinks = append(feed.AtomLinks, &RssAlternate{Rel: "hub", Href: hub})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXML(feed, &buf); err != nil {
+		log.Printf("failed to write XML of %s: %v", name, err)
+		return
+	}
+
+	filename := filepath.Join(g.dir, name)
+	hash := sha256.Sum256(buf.Bytes())
+	prev, ok := g.hashes[name]
+	if !ok {
+		// После запуска сравниваем с фидом, сохраненным предыдущим процессом
+		if data, err := os.ReadFile(filename); err == nil {
+			prev, ok = sha256.Sum256(data), true
+		}
+	}
+	if ok && prev == hash {
+		return
+	}
+
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		log.Printf("failed to create file %s: %v", filename, err)
+		return
+	}
+	g.hashes[name] = hash
+
+	for _, hub := range g.hubs {
+		if err := websub.Publish(g.client, hub, self); err != nil {
+			log.Printf("websub: %v", err)
+		}
+	}
+}
+
+// descriptionSize максимальная длина описания, составленного из контента записи
+const descriptionSize = 300
+
+// populateDescription generates description for a feed entry.
+//
+// It takes a feed.Entry as parameter and returns a string.
+// If the entry has no summary, the first sentences of the content are used.
+func populateDescription(entry feed.Entry) string {
+	description := entry.Summary
+	if description == "" {
+		content := strings.Join(strings.Fields(html.UnescapeString(striphtml.StripHtmlTags(entry.Content))), " ")
+		description = sentence.First(content, entry.Language, descriptionSize)
+	}
+	if description == "" {
+		description = entry.Title
+	}
+	return description
+}
+
+func populateAuthorField(author string, resourceID int) string {
+	if val, ok := authorMap[resourceID]; ok {
+		if resourceID == 3 && author != "" {
+			return author
+		}
+		return val
+	}
+	return author
+}
+
+func makeEntryUrl(url string, language string) string {
+	base := "https://feed.svodd.ru"
+	if language != "ru" && language != "" {
+		base += "/" + language
+	}
+	return fmt.Sprintf("%s/entry?url=%v", base, url)
+}
@@ -12,26 +12,59 @@ type RssFeedXml struct {
 	ContentNamespace string   `xml:"xmlns:content,attr,omitempty"`
 	YandexNamespace  string   `xml:"xmlns:yandex,attr"`
 	MediaNamespace   string   `xml:"xmlns:media,attr"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr"`
 	Channel          *RssFeed
 }
 
 type RssFeed struct {
-	XMLName     xml.Name   `xml:"channel"`
-	Title       string     `xml:"title,omitempty"`
-	Link        string     `xml:"link,omitempty"`
-	Description string     `xml:"description,omitempty"`
-	Items       []*RssItem `xml:"item"`
+	XMLName     xml.Name        `xml:"channel"`
+	Title       string          `xml:"title,omitempty"`
+	Link        string          `xml:"link,omitempty"`
+	Description string          `xml:"description,omitempty"`
+	AtomLinks   []*RssAlternate `xml:"atom:link,omitempty"` // Ссылки на сам фид и хабы WebSub
+	Items       []*RssItem      `xml:"item"`
 }
 
 type RssItem struct {
-	XMLName     xml.Name   `xml:"item"`
-	Title       string     `xml:"title"`
-	Link        string     `xml:"link"`
-	PubDate     string     `xml:"pubDate"`
-	Author      string     `xml:"author,omitempty"`
-	Content     string     `xml:"yandex:full-text"`
-	Description string     `xml:"description,omitempty"`
-	Source      *RssSource `xml:"source,omitempty"` // Добавляем поле для источника
+	XMLName     xml.Name           `xml:"item"`
+	Title       string             `xml:"title"`
+	Link        string             `xml:"link"`
+	PubDate     string             `xml:"pubDate"`
+	Author      string             `xml:"author,omitempty"`
+	Content     string             `xml:"yandex:full-text"`
+	Description string             `xml:"description,omitempty"`
+	Source      *RssSource         `xml:"source,omitempty"`        // Добавляем поле для источника
+	Enclosure   *RssEnclosure      `xml:"enclosure,omitempty"`     // Основное медиа записи
+	Media       []*RssMediaContent `xml:"media:content,omitempty"` // Все медиа записи
+	Alternates  []*RssAlternate    `xml:"atom:link,omitempty"`     // Переводы записи на другие языки
+}
+
+// RssAlternate ссылка atom:link: перевод записи на другой язык, сам фид или хаб WebSub
+type RssAlternate struct {
+	XMLName  xml.Name `xml:"atom:link"`
+	Rel      string   `xml:"rel,attr"`
+	Hreflang string   `xml:"hreflang,attr,omitempty"`
+	Href     string   `xml:"href,attr"`
+	Title    string   `xml:"title,attr,omitempty"`
+}
+
+// RssEnclosure вложение элемента RSS, длина файла неизвестна и указывается как 0
+type RssEnclosure struct {
+	XMLName xml.Name `xml:"enclosure"`
+	URL     string   `xml:"url,attr"`
+	Length  string   `xml:"length,attr"`
+	Type    string   `xml:"type,attr"`
+}
+
+// RssMediaContent элемент media:content из пространства имен Media RSS
+type RssMediaContent struct {
+	XMLName     xml.Name `xml:"media:content"`
+	URL         string   `xml:"url,attr"`
+	Type        string   `xml:"type,attr,omitempty"`
+	Medium      string   `xml:"medium,attr,omitempty"`
+	Width       int      `xml:"width,attr,omitempty"`
+	Height      int      `xml:"height,attr,omitempty"`
+	Description string   `xml:"media:description,omitempty"`
 }
 
 type RssSource struct {
@@ -78,5 +111,6 @@ func (rf *RssFeed) FeedXml() interface{} {
 		Channel:         rf,
 		YandexNamespace: "http://news.yandex.ru",
 		MediaNamespace:  "http://search.yahoo.com/mrss/",
+		AtomNamespace:   "http://www.w3.org/2005/Atom",
 	}
 }
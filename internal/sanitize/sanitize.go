@@ -0,0 +1,242 @@
+// Package sanitize приводит html контент записей к единому виду перед разбивкой на фрагменты:
+// оставляет только разрешенные теги, нормализует пробелы, разрешает относительные ссылки
+// и удаляет скрипты и стили вместе с содержимым.
+package sanitize
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowed разрешенные теги, остальные теги удаляются, а их текст сохраняется
+var allowed = map[string]bool{
+	"p": true, "a": true, "b": true, "i": true, "br": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "th": true, "td": true,
+	"h2": true, "h3": true, "h4": true,
+}
+
+// renamed теги, которые заменяются разрешенными аналогами
+var renamed = map[string]string{
+	"strong": "b",
+	"em":     "i",
+	"h1":     "h2",
+	"h5":     "h4",
+	"h6":     "h4",
+}
+
+// dropped теги, которые удаляются вместе с содержимым
+var dropped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"iframe": true, "object": true, "embed": true, "svg": true, "head": true,
+}
+
+// blocks блочные теги, которые не могут находиться внутри абзаца.
+// Текст вне разрешенных блоков оборачивается в абзац.
+var blocks = map[string]bool{
+	"p": true, "ul": true, "ol": true, "li": true, "blockquote": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "th": true, "td": true,
+	"h2": true, "h3": true, "h4": true,
+	// неразрешенные блочные теги только завершают абзац
+	"div": true, "section": true, "article": true, "header": true, "footer": true, "figure": true,
+	"figcaption": true, "dl": true, "dt": true, "dd": true, "pre": true, "hr": true, "center": true,
+}
+
+// textContainers разрешенные блоки, внутри которых текст не оборачивается в абзац
+var textContainers = map[string]bool{
+	"p": true, "li": true, "th": true, "td": true, "h2": true, "h3": true, "h4": true,
+}
+
+var (
+	spaceRe = regexp.MustCompile(`\s+`)
+	// blockSpaceRe пробелы вокруг блочных тегов
+	blockSpaceRe = regexp.MustCompile(`\s*(</?(?:p|ul|ol|li|blockquote|table|thead|tbody|tfoot|tr|th|td|h2|h3|h4)>|<br>)\s*`)
+	// emptyRe пустые элементы
+	emptyRe = regexp.MustCompile(`<(p|li|b|i|h2|h3|h4|blockquote|ul|ol)>(?:\s|<br>)*</(?:p|li|b|i|h2|h3|h4|blockquote|ul|ol)>`)
+)
+
+// HTML очищает html контент записи, относительные ссылки разрешаются относительно адреса записи base
+func HTML(content string, base string) string {
+	baseURL, _ := url.Parse(base)
+
+	var sb strings.Builder
+	var stack []string
+	skip := 0
+
+	// inText сообщает, что открыт блок, в котором может находиться текст
+	inText := func() bool {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if textContainers[stack[i]] {
+				return true
+			}
+			if blocks[stack[i]] {
+				return false
+			}
+		}
+		return false
+	}
+
+	closeTo := func(n int) {
+		for i := len(stack) - 1; i >= n; i-- {
+			sb.WriteString("</" + stack[i] + ">")
+		}
+		stack = stack[:n]
+	}
+
+	// closeParagraph закрывает открытый абзац перед блочным тегом
+	closeParagraph := func() {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == "p" {
+				closeTo(i)
+				return
+			}
+			if blocks[stack[i]] {
+				return
+			}
+		}
+	}
+
+	// ensureText открывает абзац, если текст или строчный тег находится вне текстового блока
+	ensureText := func() {
+		if !inText() {
+			sb.WriteString("<p>")
+			stack = append(stack, "p")
+		}
+	}
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		name := tok.Data
+		if r, ok := renamed[name]; ok {
+			name = r
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if dropped[name] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if blocks[name] {
+				closeParagraph()
+			}
+			if !allowed[name] {
+				continue
+			}
+			if name == "br" {
+				if inText() {
+					sb.WriteString("<br>")
+				}
+				continue
+			}
+			if !blocks[name] {
+				ensureText()
+			}
+
+			sb.WriteString("<" + name)
+			if name == "a" {
+				if href := resolve(baseURL, attr(tok, "href")); href != "" {
+					sb.WriteString(` href="` + html.EscapeString(href) + `"`)
+				}
+			}
+			sb.WriteString(">")
+
+			if tt == html.StartTagToken {
+				stack = append(stack, name)
+			} else {
+				sb.WriteString("</" + name + ">")
+			}
+
+		case html.EndTagToken:
+			if dropped[name] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if !allowed[name] {
+				if blocks[name] {
+					closeParagraph()
+				}
+				continue
+			}
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == name {
+					closeTo(i)
+					break
+				}
+			}
+
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := spaceRe.ReplaceAllString(tok.Data, " ")
+			if strings.TrimSpace(text) == "" {
+				if inText() {
+					sb.WriteString(" ")
+				}
+				continue
+			}
+			ensureText()
+			sb.WriteString(html.EscapeString(text))
+		}
+	}
+	closeTo(0)
+
+	result := blockSpaceRe.ReplaceAllString(sb.String(), "$1")
+	for {
+		cleaned := emptyRe.ReplaceAllString(result, "")
+		if cleaned == result {
+			break
+		}
+		result = cleaned
+	}
+
+	return strings.TrimSpace(result)
+}
+
+// attr возвращает значение атрибута key токена
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// resolve разрешает ссылку относительно адреса записи, небезопасные ссылки отбрасываются
+func resolve(base *url.URL, href string) string {
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil && base.IsAbs() {
+		u = base.ResolveReference(u)
+	}
+	switch u.Scheme {
+	case "http", "https", "mailto", "":
+		return u.String()
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package splitter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Контейнеры — блочные элементы, внутри которых можно разрезать контент.
+// При разрезании открытые контейнеры закрываются в конце фрагмента и открываются заново в начале следующего.
+var containerTags = map[string]bool{
+	"div": true, "section": true, "article": true, "blockquote": true, "figure": true,
+	"ul": true, "ol": true, "dl": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true,
+}
+
+// Листовые блоки — блочные элементы, после закрытия которых проходит граница фрагмента.
+// Внутри листового блока контент не разрезается, кроме слишком длинных блоков, см. splitLongBlock.
+var leafTags = map[string]bool{
+	"p": true, "li": true, "tr": true, "dt": true, "dd": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"caption": true, "figcaption": true, "hr": true,
+}
+
+// Элементы без закрывающего тега
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// element открытый элемент: имя, исходный открывающий тег и номер,
+// по которому отличаются разные контейнеры с одинаковой разметкой
+type element struct {
+	id   int
+	name string
+	raw  string
+}
+
+// block неделимая часть контента между границами блочных элементов
+// вместе с контейнерами, внутри которых она находится
+type block struct {
+	html    string
+	context []element
+}
+
+// parseBlocks разбирает html контент на блоки. Граница блока проходит после закрытия листового блока,
+// на открывающих и закрывающих тегах контейнеров, на <br> и переводах строк вне листовых и строчных элементов.
+// Незакрытые элементы закрываются, лишние закрывающие теги отбрасываются.
+func parseBlocks(content string) []block {
+	var blocks []block
+	var stack []element
+	var buf strings.Builder
+	var context []element
+	var nextID int
+
+	// firstInner возвращает индекс первого открытого элемента, не являющегося контейнером
+	firstInner := func() int {
+		for i, el := range stack {
+			if !containerTags[el.name] {
+				return i
+			}
+		}
+		return len(stack)
+	}
+
+	atBoundary := func() bool {
+		return firstInner() == len(stack)
+	}
+
+	write := func(s string) {
+		if buf.Len() == 0 {
+			context = append([]element(nil), stack...)
+		}
+		buf.WriteString(s)
+	}
+
+	flush := func() {
+		if strings.TrimSpace(buf.String()) != "" {
+			blocks = append(blocks, block{html: buf.String(), context: context})
+		}
+		buf.Reset()
+	}
+
+	// closeTo закрывает открытые элементы до элемента с индексом n включительно
+	closeTo := func(n int) {
+		for i := len(stack) - 1; i >= n; i-- {
+			write("</" + stack[i].name + ">")
+		}
+		stack = stack[:n]
+	}
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+
+		switch tt {
+		case html.TextToken:
+			if !atBoundary() {
+				write(raw)
+				continue
+			}
+			// Вне листовых и строчных элементов перевод строки — граница блока
+			lines := strings.SplitAfter(raw, "\n")
+			for _, line := range lines {
+				write(line)
+				if strings.HasSuffix(line, "\n") {
+					flush()
+				}
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			name := tok.Data
+
+			if voidTags[name] || tt == html.SelfClosingTagToken {
+				if atBoundary() && leafTags[name] {
+					flush()
+				}
+				write(raw)
+				if atBoundary() && (name == "br" || leafTags[name]) {
+					flush()
+				}
+				continue
+			}
+
+			nextID++
+			el := element{id: nextID, name: name, raw: raw}
+
+			// Открытие блока внутри незакрытого <p> закрывает его, как это делают браузеры
+			if k := firstInner(); k < len(stack) && stack[k].name == "p" && (leafTags[name] || containerTags[name]) {
+				closeTo(k)
+				flush()
+			}
+			if atBoundary() && containerTags[name] {
+				flush()
+				stack = append(stack, el)
+				continue
+			}
+			if atBoundary() && leafTags[name] {
+				flush()
+			}
+			write(raw)
+			stack = append(stack, el)
+
+		case html.EndTagToken:
+			name := z.Token().Data
+
+			n := -1
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == name {
+					n = i
+					break
+				}
+			}
+			// Закрывающий тег без открывающего отбрасываем
+			if n < 0 {
+				continue
+			}
+
+			// Закрытие контейнера: закрываем незакрытые элементы внутри него и завершаем блок
+			if k := firstInner(); n < k {
+				closeTo(k)
+				flush()
+				stack = stack[:n]
+				continue
+			}
+
+			closeTo(n + 1)
+			write(raw)
+			stack = stack[:n]
+
+			if atBoundary() && leafTags[name] {
+				flush()
+			}
+		}
+	}
+
+	// Закрываем элементы, оставшиеся открытыми в конце контента
+	closeTo(firstInner())
+	flush()
+
+	return blocks
+}
+
+// render собирает блоки в html фрагмент, открывая и закрывая контейнеры блоков,
+// так что фрагмент всегда содержит сбалансированную разметку
+func render(blocks []block) string {
+	var sb strings.Builder
+	var current []element
+
+	for _, b := range blocks {
+		common := 0
+		for common < len(current) && common < len(b.context) && current[common].id == b.context[common].id {
+			common++
+		}
+		for i := len(current) - 1; i >= common; i-- {
+			sb.WriteString("</" + current[i].name + ">")
+		}
+		for i := common; i < len(b.context); i++ {
+			sb.WriteString(b.context[i].raw)
+		}
+		current = b.context
+		sb.WriteString(b.html)
+	}
+
+	for i := len(current) - 1; i >= 0; i-- {
+		sb.WriteString("</" + current[i].name + ">")
+	}
+
+	return sb.String()
+}
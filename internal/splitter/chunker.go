@@ -0,0 +1,54 @@
+package splitter
+
+import (
+	"context"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/transcript"
+)
+
+// Стратегии разбивки контента на фрагменты
+const (
+	StrategySize    = "size"    // По размеру, склеивая соседние блоки
+	StrategyHeading = "heading" // По заголовкам h1-h6, каждый раздел начинает новый фрагмент
+	StrategySpeaker = "speaker" // По репликам стенограммы, каждая реплика начинает новый фрагмент
+)
+
+// Chunker делит запись на фрагменты для сохранения в поисковом индексе
+type Chunker interface {
+	SplitEntry(ctx context.Context, entry feed.Entry) []feed.Entry
+}
+
+var (
+	_ Chunker = &Splitter{}
+	_ Chunker = &HeadingSplitter{}
+	_ Chunker = &SpeakerSplitter{}
+)
+
+// HeadingSplitter начинает новый фрагмент с каждого заголовка h1-h6,
+// разделы длиннее optParSize делятся по размеру
+type HeadingSplitter struct {
+	*Splitter
+}
+
+func NewHeadingSplitter(optParSize int, maxParSize int) *HeadingSplitter {
+	sp := NewSplitter(optParSize, maxParSize)
+	sp.boundary = isHeading
+	return &HeadingSplitter{Splitter: sp}
+}
+
+// SpeakerSplitter начинает новый фрагмент с каждой реплики стенограммы или брифинга,
+// например «В.Путин: ...» или «<b>Вопрос:</b> ...», длинные реплики делятся по размеру.
+// Фрагменту присваивается говорящий реплики, к которой он относится.
+type SpeakerSplitter struct {
+	*Splitter
+}
+
+func NewSpeakerSplitter(optParSize int, maxParSize int) *SpeakerSplitter {
+	sp := NewSplitter(optParSize, maxParSize)
+	sp.boundary = func(b block) bool {
+		return transcript.Label(b.html) != ""
+	}
+	sp.speakers = true
+	return &SpeakerSplitter{Splitter: sp}
+}
@@ -2,15 +2,38 @@ package splitter
 
 import (
 	"context"
-	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"html"
 	"log"
 	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/sentence"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+	"github.com/terratensor/feed-parser/internal/transcript"
+	xhtml "golang.org/x/net/html"
 )
 
+// Единицы размера перекрытия фрагментов
+const (
+	OverlapChars     = "chars"
+	OverlapSentences = "sentences"
+)
+
+// sectionSize максимальная длина названия раздела фрагмента
+const sectionSize = 200
+
+// Splitter делит контент записи на фрагменты по размеру, не разрывая html блоки
 type Splitter struct {
-	optParSize int
-	maxParSize int
+	optParSize  int
+	maxParSize  int
+	overlap     int
+	overlapUnit string
+	// boundary сообщает, что с блока обязательно начинается новый фрагмент
+	boundary func(b block) bool
+	// speakers определять говорящего для фрагментов стенограмм
+	speakers bool
 }
 
 func NewSplitter(optParSize int, maxParSize int) *Splitter {
@@ -20,201 +43,383 @@ func NewSplitter(optParSize int, maxParSize int) *Splitter {
 	}
 }
 
+// WithOverlap задает перекрытие фрагментов: в начало каждого фрагмента, кроме первого,
+// добавляется size символов или предложений из конца предыдущего фрагмента
+func (sp *Splitter) WithOverlap(size int, unit string) *Splitter {
+	sp.overlap = size
+	sp.overlapUnit = unit
+	return sp
+}
+
+// chunk фрагмент контента, название раздела и говорящий, к которым он относится
+type chunk struct {
+	html    string
+	section string
+	speaker string
+}
+
 func (sp *Splitter) SplitEntry(ctx context.Context, entry feed.Entry) []feed.Entry {
 
 	var entries []feed.Entry
 
-	contentChunks := sp.splitContent(entry.Content)
+	contentChunks := sp.splitContent(entry.Content, entry.Language)
+
+	offset := 0
+	var prevText string
 
-	for chunk, content := range contentChunks {
+	for n, c := range contentChunks {
+		text := plainText(c.html)
+
+		// Перекрытие с предыдущим фрагментом добавляется отдельным абзацем,
+		// его длина сохраняется, чтобы при сборке записи его можно было отбросить
+		content := c.html
+		overlap := 0
+		if o := sp.overlapText(prevText, entry.Language); o != "" {
+			prefix := `<p class="overlap">` + html.EscapeString(o) + `</p>`
+			content = prefix + content
+			overlap = len(prefix)
+		}
 
 		newEntry := feed.Entry{
-			Language:   entry.Language,
-			Title:      entry.Title,
-			Url:        entry.Url,
-			Updated:    entry.Updated,
-			Published:  entry.Published,
-			Summary:    entry.Summary,
-			Content:    content,
-			Author:     entry.Author,
-			Number:     entry.Number,
-			ResourceID: entry.ResourceID,
-			Created:    entry.Created,
-			Chunk:      chunk + 1,
+			Language:           entry.Language,
+			Title:              entry.Title,
+			Url:                entry.Url,
+			Updated:            entry.Updated,
+			Published:          entry.Published,
+			Summary:            entry.Summary,
+			Content:            content,
+			Author:             entry.Author,
+			Number:             entry.Number,
+			ResourceID:         entry.ResourceID,
+			Created:            entry.Created,
+			Chunk:              n + 1,
+			ChunkTotal:         len(contentChunks),
+			ChunkOffset:        offset,
+			Overlap:            overlap,
+			Section:            c.section,
+			Speaker:            c.speaker,
+			Media:              entry.Media,
+			Attachment:         entry.Attachment,
+			DetectedLanguage:   entry.DetectedLanguage,
+			LanguageConfidence: entry.LanguageConfidence,
+			GroupID:            entry.GroupID,
+			Fingerprint:        entry.Fingerprint,
+			DuplicateOf:        entry.DuplicateOf,
+			Tags:               entry.Tags,
 		}
 
 		entries = append(entries, newEntry)
+
+		offset += utf8.RuneCountInString(text)
+		prevText = text
 	}
 	return entries
 }
 
-func (sp *Splitter) splitContent(entryContent string) []string {
-
-	var contentBuilder strings.Builder
-	contentBuilder.WriteString(entryContent)
-
-	var builder strings.Builder
-	var pars []string
-
-	entryContentLen := utf8.RuneCountInString(contentBuilder.String())
-	if entryContentLen > sp.maxParSize {
-
-		// Заменяем строковый разделитель \n на обычный
-		content := strings.Replace(contentBuilder.String(), `\n`, "\n", -1)
-		contentBuilder.Reset()
-		contentBuilder.WriteString(content)
-
-		// Задаем разделители
-		separators := []string{"\n", "<br>"}
-		// Обрабатываем стандартный сценарий, делим по параграфам
-		paragraphs := strings.SplitAfter(contentBuilder.String(), "</p>")
-
-		var completed bool
-
-		for _, paragraph := range paragraphs {
-			completed = false
-			// Если полученный параграф больше оптимального размера, то разбиваем его на части
-			// по ранее заданному списку разделителей
-			if utf8.RuneCountInString(paragraph) > sp.maxParSize {
-				for _, separator := range separators {
-
-					newParagraphs := strings.SplitAfter(paragraph, separator)
-					// если после использованного разделителя получили 1 параграф,
-					// то пропускаем и применяем следующий разделитель
-					if len(newParagraphs) == 1 {
-						continue
-					}
-					log.Printf("🚩🚩 Успешно обработано  по разделителю `%v`", separator)
-					log.Printf("кол-во параграфов %v, %v", len(newParagraphs), newParagraphs)
-					pars = append(pars, sp.processNewParagraphs(newParagraphs, &builder, separator)...)
-					completed = true
+// overlapText возвращает конец текста предыдущего фрагмента для перекрытия.
+// Перекрытие в предложениях не длиннее половины оптимального размера фрагмента: если в тексте нет
+// границ предложений, берется конец текста этой длины, а не весь предыдущий фрагмент.
+func (sp *Splitter) overlapText(prev, lang string) string {
+	if sp.overlap <= 0 || prev == "" {
+		return ""
+	}
+
+	if sp.overlapUnit == OverlapSentences {
+		parts := sentence.Split(prev, lang)
+		if len(parts) > sp.overlap {
+			parts = parts[len(parts)-sp.overlap:]
+		}
+		return tail(strings.TrimSpace(strings.Join(parts, "")), sp.optParSize/2)
+	}
+
+	return tail(prev, sp.overlap)
+}
+
+// tail возвращает последние size символов текста, не начиная с середины слова
+func tail(text string, size int) string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return text
+	}
+	t := string(runes[len(runes)-size:])
+	if i := strings.IndexFunc(t, unicode.IsSpace); i >= 0 {
+		t = t[i:]
+	}
+	return strings.TrimSpace(t)
+}
+
+// splitContent делит контент на фрагменты по границам html блоков,
+// каждый фрагмент содержит сбалансированную разметку.
+// Разделом фрагмента считается последний заголовок h1-h6 перед ним или в его начале,
+// если заголовка нет — первое предложение фрагмента.
+func (sp *Splitter) splitContent(entryContent, lang string) []chunk {
+	if utf8.RuneCountInString(entryContent) <= sp.maxParSize {
+		section, speaker := "", ""
+		blocks := parseBlocks(entryContent)
+		if len(blocks) > 0 && isHeading(blocks[0]) {
+			section = plainText(blocks[0].html)
+		}
+		if sp.speakers {
+			for _, b := range blocks {
+				if speaker = transcript.Label(b.html); speaker != "" {
 					break
 				}
-			} else {
-				builder.WriteString(paragraph)
 			}
+		}
+		log.Printf("🚩 итого количество фрагментов в параграфе: %v", 1)
+		return []chunk{{html: entryContent, section: sp.section(section, entryContent, lang), speaker: speaker}}
+	}
 
-			// если проверки сверху не сработали записываем параграф как есть
-			if utf8.RuneCountInString(paragraph) > sp.maxParSize && !completed {
-				log.Printf("оставляем длинный параграф как есть: %v, %v", utf8.RuneCountInString(paragraph), paragraph)
-				builder.WriteString(paragraph)
-			}
+	// Заменяем строковый разделитель \n на обычный
+	content := strings.Replace(entryContent, `\n`, "\n", -1)
 
-			if utf8.RuneCountInString(builder.String()) > sp.optParSize {
-				pars = append(pars, builder.String())
-				//log.Printf("🚩 полученный chunk: %v", utf8.RuneCountInString(builder.String()))
-				builder.Reset()
+	var blocks []block
+	for _, b := range parseBlocks(content) {
+		// Слишком длинный блок делим по предложениям
+		if utf8.RuneCountInString(b.html) > sp.maxParSize {
+			blocks = append(blocks, sp.splitLongBlock(b, lang)...)
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+
+	var pars []chunk
+	var current, last []block
+	var heading, section string
+	// speaker говорящий текущей реплики, chunkSpeaker — говорящий фрагмента current
+	var speaker, chunkSpeaker string
+	// forced фрагмент current начат на обязательной границе
+	forced := false
+	size := 0
+
+	for _, b := range blocks {
+		if len(current) > 0 && sp.boundary != nil && sp.boundary(b) {
+			pars = append(pars, chunk{html: render(current), section: section, speaker: chunkSpeaker})
+			last = current
+			current = nil
+			size = 0
+			forced = true
+		}
+		if isHeading(b) {
+			heading = plainText(b.html)
+		}
+		if sp.speakers {
+			if label := transcript.Label(b.html); label != "" {
+				speaker = label
 			}
 		}
+		// Блок не помещается в текущий фрагмент, не превышая максимальный размер
+		if len(current) > 0 && utf8.RuneCountInString(render(append(current[:len(current):len(current)], b))) > sp.maxParSize {
+			pars = append(pars, chunk{html: render(current), section: section, speaker: chunkSpeaker})
+			last = current
+			current = nil
+			size = 0
+			forced = false
+		}
+		if len(current) == 0 {
+			section = heading
+			chunkSpeaker = speaker
+		}
 
-		if utf8.RuneCountInString(builder.String()) > 0 {
-			//log.Printf("🚩🚩 полученный остаток chunk: %v", utf8.RuneCountInString(builder.String()))
-			//log.Printf("(builder.String()) %v", builder.String())
-			lastPar := pars[len(pars)-1]
-			pars = pars[:len(pars)-1]
-			newLastPar := lastPar + builder.String()
-			//log.Printf("🚩🚩🚩🚩 %v", utf8.RuneCountInString(newLastPar))
-			pars = append(pars, newLastPar)
+		current = append(current, b)
+		size += utf8.RuneCountInString(b.html)
+
+		if size > sp.optParSize {
+			pars = append(pars, chunk{html: render(current), section: section, speaker: chunkSpeaker})
+			last = current
+			current = nil
+			size = 0
+			forced = false
 		}
+	}
 
-		if len(pars) == 1 && utf8.RuneCountInString(pars[0]) > sp.maxParSize+sp.optParSize {
-			log.Printf("🚩🚩 запускаем разделение по предложениям %v", pars[0])
-			var longBuilder strings.Builder
-			longBuilder.WriteString(pars[0])
-			pars = sp.splitLongParagraph(&longBuilder)
+	// Остаток присоединяем к последнему фрагменту, если он не начат на обязательной границе
+	// и объединенный фрагмент не превышает максимальный размер
+	if len(current) > 0 {
+		merged := ""
+		if len(pars) > 0 && !forced {
+			merged = render(append(last, current...))
 		}
-	} else {
-		pars = append(pars, contentBuilder.String())
-		builder.Reset()
-		contentBuilder.Reset()
+		if merged != "" && utf8.RuneCountInString(merged) <= sp.maxParSize {
+			pars[len(pars)-1].html = merged
+		} else {
+			pars = append(pars, chunk{html: render(current), section: section, speaker: chunkSpeaker})
+		}
+	}
+
+	for n := range pars {
+		pars[n].section = sp.section(pars[n].section, pars[n].html, lang)
 	}
 
-	count := len(pars)
-	//if count > 1 {
-	log.Printf("🚩 итого количество фрагментов в параграфе: %v", count)
-	//}
+	log.Printf("🚩 итого количество фрагментов в параграфе: %v", len(pars))
 
 	return pars
 }
 
-func (sp *Splitter) processNewParagraphs(newParagraphs []string, builder *strings.Builder, separator string) []string {
-	var result []string
-	for _, newParagraph := range newParagraphs {
-
-		builder.WriteString("<p>")
-
-		cleanParagraph := strings.Replace(newParagraph, separator, "", -1)
-		builder.WriteString(cleanParagraph)
-
-		builder.WriteString("</p>")
-
-		if utf8.RuneCountInString(builder.String()) > sp.optParSize {
-			result = append(result, builder.String())
-			builder.Reset()
-		}
+// section возвращает заголовок раздела, а если он пуст — первое предложение фрагмента,
+// сокращенные до sectionSize символов
+func (sp *Splitter) section(heading, content, lang string) string {
+	if heading != "" {
+		return sentence.First(heading, lang, sectionSize)
 	}
-
-	return result
+	parts := sentence.Split(plainText(content), lang)
+	if len(parts) == 0 {
+		return ""
+	}
+	return sentence.First(parts[0], lang, sectionSize)
 }
 
-func (sp *Splitter) splitLongParagraph(longBuilder *strings.Builder) []string {
-
-	count := utf8.RuneCountInString(longBuilder.String())
-	log.Printf("🚩🚩🚩🚩 обрабатываем длинный фрагмент: %v\n", count)
-
-	var pars []string
-
-	var builder strings.Builder
-	result := longBuilder.String()
-	result = strings.TrimPrefix(result, "<div>")
-	//result = strings.TrimPrefix(result, "<p>")
-	result = strings.TrimSuffix(result, "</div>")
-	//result = strings.TrimSuffix(result, "</p>")
+// isHeading сообщает, что блок является заголовком h1-h6
+func isHeading(b block) bool {
+	s := strings.TrimSpace(b.html)
+	return len(s) > 3 && s[0] == '<' && (s[1] == 'h' || s[1] == 'H') && s[2] >= '1' && s[2] <= '6'
+}
 
-	// sentences []string Делим параграф на предложения, разделитель точка
-	sentences := strings.SplitAfter(result, ".")
+// plainText возвращает текст фрагмента без разметки с нормализованными пробелами,
+// теги заменяются пробелами, чтобы не склеивать текст соседних блоков
+func plainText(content string) string {
+	text := striphtml.StripHtmlTags(strings.ReplaceAll(content, "<", " <"))
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
 
-	longBuilder.Reset()
+// cut позиция в html блока, на которой его можно разрезать, и блочные элементы, открытые в этой позиции
+type cut struct {
+	pos  int
+	open []element
+}
 
-	for _, sentence := range sentences {
+// splitLongBlock делит длинный блок на части по предложениям с учетом языка записи.
+// Текст делится только вне строчных элементов, в том числе внутри вложенных блоков
+// вроде <li><p> и <blockquote><p>: открытые блочные элементы закрываются в конце части
+// и открываются заново в начале следующей. Предложения длиннее optParSize делятся по словам.
+func (sp *Splitter) splitLongBlock(b block, lang string) []block {
+	log.Printf("🚩🚩 обрабатываем длинный блок: %v", utf8.RuneCountInString(b.html))
+
+	var stack []element
+	// inline количество открытых строчных элементов, внутри которых блок не разрезается
+	inline := 0
+	cuts := []cut{{pos: 0}}
+	addCut := func(pos int) {
+		if pos > cuts[len(cuts)-1].pos {
+			cuts = append(cuts, cut{pos: pos, open: append([]element(nil), stack...)})
+		}
+	}
 
-		sentence = strings.TrimSpace(sentence)
+	pos := 0
+	z := xhtml.NewTokenizer(strings.NewReader(b.html))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+		start := pos
+		pos += len(raw)
+
+		switch tt {
+		case xhtml.StartTagToken:
+			name, _ := z.TagName()
+			if voidTags[string(name)] {
+				if inline == 0 && string(name) == "br" {
+					addCut(pos)
+				}
+				continue
+			}
+			if !isBlockTag(string(name)) {
+				inline++
+			}
+			stack = append(stack, element{name: string(name), raw: raw})
+		case xhtml.EndTagToken:
+			name, _ := z.TagName()
+			n := len(stack) - 1
+			for n >= 0 && stack[n].name != string(name) {
+				n--
+			}
+			if n < 0 {
+				continue
+			}
+			for _, el := range stack[n:] {
+				if !isBlockTag(el.name) {
+					inline--
+				}
+			}
+			stack = stack[:n]
+			if inline == 0 && isBlockTag(string(name)) {
+				addCut(pos)
+			}
+		case xhtml.TextToken:
+			if inline > 0 {
+				continue
+			}
+			off := start
+			parts := sentence.Split(raw, lang)
+			for i, s := range parts {
+				for _, k := range wordCuts(s, sp.optParSize) {
+					addCut(off + k)
+				}
+				off += len(s)
+				if i < len(parts)-1 || sentence.Ends(s, lang) {
+					addCut(off)
+				}
+			}
+		}
+	}
+	addCut(len(b.html))
 
-		if (utf8.RuneCountInString(builder.String()) + utf8.RuneCountInString(sentence)) < sp.optParSize {
-			builder.WriteString(sentence)
-			builder.WriteString(" ")
-		} else {
-			longBuilder.WriteString("<p>")
-			longBuilder.WriteString(strings.TrimSpace(builder.String()))
-			longBuilder.WriteString("</p>")
-			pars = append(pars, longBuilder.String())
-			builder.Reset()
-			longBuilder.Reset()
+	// piece собирает часть блока между позициями from и to со сбалансированной разметкой
+	piece := func(from, to cut) string {
+		var sb strings.Builder
+		for _, el := range from.open {
+			sb.WriteString(el.raw)
+		}
+		sb.WriteString(b.html[from.pos:to.pos])
+		for i := len(to.open) - 1; i >= 0; i-- {
+			sb.WriteString("</" + to.open[i].name + ">")
 		}
+		return sb.String()
 	}
 
-	if utf8.RuneCountInString(builder.String()) > 0 {
-		longBuilder.WriteString("<p>")
-		longBuilder.WriteString(strings.TrimSpace(builder.String()))
-		longBuilder.WriteString("</p>")
+	var result []block
+	emit := func(from, to cut) {
+		if strings.TrimSpace(b.html[from.pos:to.pos]) != "" {
+			result = append(result, block{html: piece(from, to), context: b.context})
+		}
+	}
 
-		pars = append(pars, longBuilder.String())
-		builder.Reset()
-		longBuilder.Reset()
+	from := 0
+	for to := 2; to < len(cuts); to++ {
+		if utf8.RuneCountInString(piece(cuts[from], cuts[to])) > sp.optParSize {
+			emit(cuts[from], cuts[to-1])
+			from = to - 1
+		}
 	}
+	emit(cuts[from], cuts[len(cuts)-1])
 
-	return pars
+	return result
 }
 
-func trimPrefixes(longBuilder strings.Builder) {
-	result := longBuilder.String()
-	longBuilder.Reset()
-
-	result = strings.TrimPrefix(result, "<div>")
-	result = strings.TrimSuffix(result, "</div>")
-
-	result = strings.TrimPrefix(result, "<p>")
-	result = strings.TrimSuffix(result, "</p>")
+// isBlockTag сообщает, что элемент блочный и внутри него можно разрезать текст
+func isBlockTag(name string) bool {
+	return leafTags[name] || containerTags[name]
+}
 
-	longBuilder.WriteString(result)
+// wordCuts возвращает смещения в байтах начала слов текста длиннее size символов,
+// по которым его можно разрезать. Текст не длиннее size не делится.
+func wordCuts(text string, size int) []int {
+	if utf8.RuneCountInString(text) <= size {
+		return nil
+	}
+	var result []int
+	space := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && i > 0 {
+			result = append(result, i)
+		}
+		space = false
+	}
+	return result
 }
@@ -0,0 +1,79 @@
+package splitter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// sentences возвращает n одинаковых предложений длиной 55 символов
+func sentences(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString("Стороны обсудили вопросы двустороннего сотрудничества. ")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func TestSplitEntryNestedLongBlocks(t *testing.T) {
+	const opt, max = 250, 500
+
+	tests := []struct {
+		name    string
+		content string
+		prefix  string
+	}{
+		{"li p", "<ul><li><p>" + sentences(20) + "</p></li></ul>", "<ul><li><p>"},
+		{"blockquote p", "<blockquote><p>" + sentences(20) + "</p></blockquote>", "<blockquote><p>"},
+		{"li blockquote p", "<ol><li><blockquote><p>" + sentences(10) + "</p><p>" + sentences(10) + "</p></blockquote></li></ol>", "<ol><li><blockquote><p>"},
+		{"inline element", "<p>" + sentences(10) + " <a href=\"/\">ссылка</a> " + sentences(10) + "</p>", "<p>"},
+		{"sentence without end", "<ul><li><p>" + strings.Repeat("слово ", 150) + "</p></li></ul>", "<ul><li><p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := NewSplitter(opt, max).SplitEntry(context.Background(), feed.Entry{Content: tt.content, Language: "ru"})
+			if len(entries) < 2 {
+				t.Fatalf("chunks = %d, want the long block to be split", len(entries))
+			}
+
+			var text []string
+			for _, e := range entries {
+				if n := utf8.RuneCountInString(e.Content); n > max {
+					t.Errorf("chunk %d has %d runes, want at most %d: %q", e.Chunk, n, max, e.Content)
+				}
+				if !strings.HasPrefix(e.Content, tt.prefix) {
+					t.Errorf("chunk %d = %q, want prefix %q", e.Chunk, e.Content, tt.prefix)
+				}
+				text = append(text, plainText(e.Content))
+			}
+			if got, want := strings.Join(text, " "), plainText(tt.content); got != want {
+				t.Errorf("chunks text = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSplitEntryRemainderWithinMax(t *testing.T) {
+	const opt, max = 250, 500
+
+	var sb strings.Builder
+	for i := 0; i < 4; i++ {
+		sb.WriteString("<p>" + sentences(4) + "</p>")
+	}
+	// Остаток не помещается в последний фрагмент
+	sb.WriteString("<p>" + sentences(2) + "</p>")
+
+	entries := NewSplitter(opt, max).SplitEntry(context.Background(), feed.Entry{Content: sb.String(), Language: "ru"})
+	if len(entries) != 3 {
+		t.Errorf("chunks = %d, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if n := utf8.RuneCountInString(e.Content); n > max {
+			t.Errorf("chunk %d has %d runes, want at most %d", e.Chunk, n, max)
+		}
+	}
+}
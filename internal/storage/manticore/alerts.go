@@ -0,0 +1,211 @@
+package manticore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	openapiclient "github.com/manticoresoftware/manticoresearch-go"
+	"github.com/terratensor/feed-parser/internal/alerts"
+)
+
+// AlertStore хранит сохраненные запросы оповещений в таблице мантикоры типа pq
+// и проверяет по ним записи запросом CALL PQ. В таблице pq нет колонок для произвольных данных,
+// поэтому имя вебхука запроса хранится в таблице <Table>_webhooks под тем же идентификатором.
+type AlertStore struct {
+	apiClient *openapiclient.APIClient
+	Table     string
+	Webhooks  string
+}
+
+var (
+	resourceFilterRe = regexp.MustCompile(`resource_id=(\d+)`)
+	languageFilterRe = regexp.MustCompile(`language='([^']*)'`)
+)
+
+// NewAlertStore создает хранилище сохраненных запросов, если таблиц tbl и tbl_webhooks нет, то они создаются
+func NewAlertStore(tbl string) (*AlertStore, error) {
+	apiClient := newAPIClient()
+	webhooks := tbl + "_webhooks"
+
+	queries := []string{
+		fmt.Sprintf(`create table if not exists %v(title text, summary text, content text, resource_id int, language string) type='pq' morphology='stem_en, stem_ru'`, tbl),
+		fmt.Sprintf(`create table if not exists %v(webhook string)`, webhooks),
+	}
+	for _, query := range queries {
+		if _, _, err := apiClient.UtilsAPI.Sql(context.Background()).Body(query).RawResponse(true).Execute(); err != nil {
+			return nil, fmt.Errorf("failed to create alerts table: %v", err)
+		}
+	}
+
+	return &AlertStore{apiClient: apiClient, Table: tbl, Webhooks: webhooks}, nil
+}
+
+// Add сохраняет запрос, идентификатор запроса присваивается s.ID
+func (a *AlertStore) Add(ctx context.Context, s *alerts.Subscription) error {
+	id, err := newAlertID()
+	if err != nil {
+		return fmt.Errorf("failed to generate alert id: %v", err)
+	}
+	s.ID = id
+
+	// Вебхук сохраняется первым, чтобы запрос без вебхука не попал в проверку записей
+	body := fmt.Sprintf("INSERT INTO %v(id, webhook) VALUES (%d, '%v')", a.Webhooks, s.ID, escapeString(s.Webhook))
+	if _, _, err := a.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute(); err != nil {
+		return fmt.Errorf("failed to insert alert webhook: %v", err)
+	}
+
+	var filters []string
+	if s.ResourceID != 0 {
+		filters = append(filters, fmt.Sprintf("resource_id=%d", s.ResourceID))
+	}
+	if s.Language != "" {
+		filters = append(filters, fmt.Sprintf("language='%v'", escapeString(s.Language)))
+	}
+
+	body = fmt.Sprintf("INSERT INTO %v(id, query, filters) VALUES (%d, '%v', '%v')",
+		a.Table, s.ID, escapeString(s.Query), escapeString(strings.Join(filters, " AND ")))
+	if _, _, err := a.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute(); err != nil {
+		a.deleteWebhook(ctx, s.ID)
+		return fmt.Errorf("failed to insert alert: %v", err)
+	}
+	return nil
+}
+
+// List возвращает все сохраненные запросы
+func (a *AlertStore) List(ctx context.Context) ([]alerts.Subscription, error) {
+	return a.rows(ctx, fmt.Sprintf("SELECT * FROM %v", a.Table))
+}
+
+// Delete удаляет сохраненный запрос и его вебхук
+func (a *AlertStore) Delete(ctx context.Context, id int64) error {
+	body := fmt.Sprintf("DELETE FROM %v WHERE id=%d", a.Table, id)
+	if _, _, err := a.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute(); err != nil {
+		return fmt.Errorf("failed to delete alert %d: %v", id, err)
+	}
+	return a.deleteWebhook(ctx, id)
+}
+
+// deleteWebhook удаляет вебхук запроса id
+func (a *AlertStore) deleteWebhook(ctx context.Context, id int64) error {
+	body := fmt.Sprintf("DELETE FROM %v WHERE id=%d", a.Webhooks, id)
+	if _, _, err := a.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute(); err != nil {
+		return fmt.Errorf("failed to delete alert %d webhook: %v", id, err)
+	}
+	return nil
+}
+
+// Match возвращает сохраненные запросы, под которые подходит документ
+func (a *AlertStore) Match(ctx context.Context, doc alerts.Document) ([]alerts.Subscription, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("CALL PQ('%v', '%v', 1 as docs_json, 1 as query)", a.Table, escapeString(string(data)))
+	return a.rows(ctx, body)
+}
+
+// rows выполняет запрос body и преобразует строки ответа с колонками id, query и filters в запросы,
+// вебхуки запросов берутся из таблицы Webhooks. Запросы, сохраненные до появления этой таблицы,
+// хранят вебхук в колонке tags.
+func (a *AlertStore) rows(ctx context.Context, body string) ([]alerts.Subscription, error) {
+	data, err := a.query(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []alerts.Subscription
+	var ids []string
+	for _, row := range data {
+		s := alerts.Subscription{ID: rowID(row)}
+		s.Query, _ = row["query"].(string)
+		s.Webhook, _ = row["tags"].(string)
+		filters, _ := row["filters"].(string)
+		if m := resourceFilterRe.FindStringSubmatch(filters); m != nil {
+			s.ResourceID, _ = strconv.Atoi(m[1])
+		}
+		if m := languageFilterRe.FindStringSubmatch(filters); m != nil {
+			s.Language = m[1]
+		}
+		subs = append(subs, s)
+		ids = append(ids, strconv.FormatInt(s.ID, 10))
+	}
+	if len(subs) == 0 {
+		return subs, nil
+	}
+
+	data, err = a.query(ctx, fmt.Sprintf("SELECT id, webhook FROM %v WHERE id IN (%v) LIMIT %d OPTION max_matches=%d",
+		a.Webhooks, strings.Join(ids, ","), len(ids), len(ids)))
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make(map[int64]string, len(data))
+	for _, row := range data {
+		webhooks[rowID(row)], _ = row["webhook"].(string)
+	}
+	for n := range subs {
+		if w, ok := webhooks[subs[n].ID]; ok {
+			subs[n].Webhook = w
+		}
+	}
+	return subs, nil
+}
+
+// query выполняет запрос body и возвращает строки ответа
+func (a *AlertStore) query(ctx context.Context, body string) ([]map[string]interface{}, error) {
+	resp, _, err := a.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("error when calling `UtilsAPI.Sql`: %v", err)
+	}
+	if len(resp) == 0 {
+		return nil, nil
+	}
+	data, ok := resp[0]["data"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	rows := make([]map[string]interface{}, 0, len(data))
+	for _, r := range data {
+		if row, ok := r.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// rowID возвращает колонку id строки ответа
+func rowID(row map[string]interface{}) int64 {
+	switch v := row["id"].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		id, _ := strconv.ParseInt(v, 10, 64)
+		return id
+	}
+	return 0
+}
+
+// newAlertID возвращает случайный положительный идентификатор запроса не больше 2^53,
+// чтобы он без потерь передавался числом JSON в ответах мантикоры
+func newAlertID() (int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	id := int64(binary.BigEndian.Uint64(b[:]) >> 11)
+	if id == 0 {
+		id = 1
+	}
+	return id, nil
+}
+
+// escapeString экранирует строку для использования в одинарных кавычках в запросе SQL
+func escapeString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
@@ -133,3 +133,53 @@ func getIntervalDate(duration time.Duration) int64 {
 	intervalDate := currentTime - int64(duration.Seconds())
 	return intervalDate
 }
+
+// ResourceStat содержит количество записей и фрагментов ресурса на одном языке
+type ResourceStat struct {
+	ResourceID int    `json:"resource_id"`
+	Language   string `json:"language"`
+	Entries    int    `json:"entries"`
+	Chunks     int    `json:"chunks"`
+}
+
+// Stats возвращает количество записей (первых фрагментов) и всех фрагментов,
+// сгруппированных по ресурсу и языку
+func (c *Client) Stats(ctx context.Context) ([]ResourceStat, error) {
+
+	body := fmt.Sprintf("SELECT resource_id, language, SUM(IF(chunk=1, 1, 0)) AS entries, count(*) AS chunks FROM %v GROUP BY resource_id, language ORDER BY resource_id ASC LIMIT 1000", c.Index)
+
+	resp, _, err := c.apiClient.UtilsAPI.Sql(ctx).Body(body).RawResponse(true).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("error when calling `UtilsAPI.Sql`: %v", err)
+	}
+
+	var stats []ResourceStat
+	if len(resp) == 0 {
+		return stats, nil
+	}
+
+	data, ok := resp[0]["data"].([]interface{})
+	if !ok {
+		return stats, nil
+	}
+
+	for _, rows := range data {
+		row := rows.(map[string]interface{})
+		stat := ResourceStat{}
+		if v, ok := row["resource_id"].(float64); ok {
+			stat.ResourceID = int(v)
+		}
+		if v, ok := row["language"].(string); ok {
+			stat.Language = v
+		}
+		if v, ok := row["entries"].(float64); ok {
+			stat.Entries = int(v)
+		}
+		if v, ok := row["chunks"].(float64); ok {
+			stat.Chunks = int(v)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,128 @@
+package manticore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	openapiclient "github.com/manticoresoftware/manticoresearch-go"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// FindByGroup возвращает первые фрагменты записей группы переводов groupID
+func (c *Client) FindByGroup(ctx context.Context, groupID int64) ([]feed.Entry, error) {
+	query := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": []map[string]interface{}{
+				{"equals": map[string]interface{}{"group_id": groupID}},
+				{"equals": map[string]interface{}{"chunk": 1}},
+			},
+		},
+	}
+	return c.search(ctx, query, 100)
+}
+
+// FindPublished возвращает первые фрагменты записей ресурса resourceID,
+// опубликованных в интервале от from до to включительно, resourceID 0 — всех ресурсов
+func (c *Client) FindPublished(ctx context.Context, resourceID int, from time.Time, to time.Time) ([]feed.Entry, error) {
+	must := []map[string]interface{}{
+		{"equals": map[string]interface{}{"chunk": 1}},
+		{"range": map[string]interface{}{"published": map[string]interface{}{"gte": from.Unix(), "lte": to.Unix()}}},
+	}
+	if resourceID != 0 {
+		must = append(must, map[string]interface{}{"equals": map[string]interface{}{"resource_id": resourceID}})
+	}
+	query := map[string]interface{}{"bool": map[string]interface{}{"must": must}}
+	return c.search(ctx, query, 10000)
+}
+
+// FindByTags возвращает фрагменты записей, в которых упомянута хотя бы одна из сущностей tags,
+// если задан query, то только фрагменты, найденные по полнотекстовому запросу
+func (c *Client) FindByTags(ctx context.Context, tags []int64, query string, limit int) ([]feed.Entry, error) {
+	must := []map[string]interface{}{
+		{"in": map[string]interface{}{"tags": tags}},
+	}
+	if query != "" {
+		must = append(must, map[string]interface{}{"match": map[string]interface{}{"*": query}})
+	}
+	q := map[string]interface{}{"bool": map[string]interface{}{"must": must}}
+	return c.search(ctx, q, limit)
+}
+
+// search выполняет запрос query и возвращает найденные фрагменты по порядку публикации
+func (c *Client) search(ctx context.Context, query map[string]interface{}, limit int) ([]feed.Entry, error) {
+	searchRequest := *openapiclient.NewSearchRequest(c.Index)
+	searchRequest.SetQuery(query)
+	searchRequest.SetLimit(int32(limit))
+	searchRequest.SetMaxMatches(int32(limit))
+	searchRequest.SetSort([]map[string]interface{}{{"published": "asc"}})
+
+	_, r, err := c.apiClient.SearchAPI.Search(ctx).SearchRequest(searchRequest).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("error when calling `SearchAPI.Search`: %v | query: %v", err, query)
+	}
+	defer r.Body.Close()
+
+	res := &Response{}
+	respBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(respBody, res); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	var entries []feed.Entry
+	for _, hit := range res.Hits.Hits {
+		jsonData, err := json.Marshal(hit.Source)
+		if err != nil {
+			return nil, err
+		}
+		var dbe DBEntry
+		if err = json.Unmarshal(jsonData, &dbe); err != nil {
+			return nil, err
+		}
+		entries = append(entries, dbe.entry(hit.Id))
+	}
+	return entries, nil
+}
+
+// entry преобразует запись таблицы в запись ленты
+func (dbe DBEntry) entry(id int64) feed.Entry {
+	updated := time.Unix(dbe.Updated, 0)
+	published := time.Unix(dbe.Published, 0)
+	created := time.Unix(dbe.Created, 0)
+	updatedAt := time.Unix(dbe.UpdatedAt, 0)
+
+	return feed.Entry{
+		ID:                 &id,
+		Language:           dbe.Language,
+		Title:              dbe.Title,
+		Url:                dbe.Url,
+		Updated:            &updated,
+		Published:          &published,
+		Summary:            dbe.Summary,
+		Content:            dbe.Content,
+		Author:             dbe.Author,
+		Number:             dbe.Number,
+		ResourceID:         dbe.ResourceID,
+		Created:            &created,
+		Chunk:              dbe.Chunk,
+		ChunkTotal:         dbe.ChunkTotal,
+		ChunkOffset:        dbe.ChunkOffset,
+		Overlap:            dbe.Overlap,
+		Section:            dbe.Section,
+		Speaker:            dbe.Speaker,
+		Attachment:         dbe.Attachment,
+		DetectedLanguage:   dbe.DetectedLanguage,
+		LanguageConfidence: dbe.LanguageConfidence,
+		GroupID:            dbe.GroupID,
+		Fingerprint:        dbe.Fingerprint,
+		DuplicateOf:        dbe.DuplicateOf,
+		Tags:               dbe.Tags,
+		Media:              dbe.Media,
+		UpdatedAt:          &updatedAt,
+	}
+}
@@ -27,19 +27,32 @@ type Response struct {
 			Id     int64 `json:"_id"`
 			Score  int   `json:"_score"`
 			Source struct {
-				Title      string `json:"title"`
-				Summary    string `json:"summary"`
-				Content    string `json:"content"`
-				ResourceID int    `json:"resource_id"`
-				Chunk      int    `json:"chunk"`
-				Published  int64  `json:"published"`
-				Updated    int64  `json:"updated"`
-				Created    int64  `json:"created"`
-				UpdatedAt  int64  `json:"updated_at"`
-				Language   string `json:"language"`
-				Url        string `json:"url"`
-				Author     string `json:"author"`
-				Number     string `json:"number"`
+				Title              string  `json:"title"`
+				Summary            string  `json:"summary"`
+				Content            string  `json:"content"`
+				ResourceID         int     `json:"resource_id"`
+				Chunk              int     `json:"chunk"`
+				ChunkTotal         int     `json:"chunk_total"`
+				ChunkOffset        int     `json:"chunk_offset"`
+				Overlap            int     `json:"overlap"`
+				Section            string  `json:"section"`
+				Speaker            string  `json:"speaker"`
+				Attachment         string  `json:"attachment"`
+				DetectedLanguage   string  `json:"detected_language"`
+				LanguageConfidence float64 `json:"language_confidence"`
+				GroupID            int64   `json:"group_id"`
+				Fingerprint        int64   `json:"fingerprint"`
+				DuplicateOf        string  `json:"duplicate_of"`
+				Tags               []int64 `json:"tags"`
+				Media              Media   `json:"media"`
+				Published          int64   `json:"published"`
+				Updated            int64   `json:"updated"`
+				Created            int64   `json:"created"`
+				UpdatedAt          int64   `json:"updated_at"`
+				Language           string  `json:"language"`
+				Url                string  `json:"url"`
+				Author             string  `json:"author"`
+				Number             string  `json:"number"`
 			} `json:"_source"`
 		} `json:"hits"`
 	} `json:"hits"`
@@ -54,19 +67,32 @@ type IndexStatus struct {
 }
 
 type DBEntry struct {
-	Language   string `json:"language"`
-	Title      string `json:"title"`
-	Url        string `json:"url"`
-	Updated    int64  `json:"updated"`
-	Published  int64  `json:"published"`
-	Summary    string `json:"summary"`
-	Content    string `json:"content"`
-	Author     string `json:"author"`
-	Number     string `json:"number"`
-	ResourceID int    `json:"resource_id"`
-	Created    int64  `json:"created"`
-	Chunk      int    `json:"chunk"`
-	UpdatedAt  int64  `json:"updated_at"`
+	Language           string  `json:"language"`
+	Title              string  `json:"title"`
+	Url                string  `json:"url"`
+	Updated            int64   `json:"updated"`
+	Published          int64   `json:"published"`
+	Summary            string  `json:"summary"`
+	Content            string  `json:"content"`
+	Author             string  `json:"author"`
+	Number             string  `json:"number"`
+	ResourceID         int     `json:"resource_id"`
+	Created            int64   `json:"created"`
+	Chunk              int     `json:"chunk"`
+	ChunkTotal         int     `json:"chunk_total"`
+	ChunkOffset        int     `json:"chunk_offset"`
+	Overlap            int     `json:"overlap"`
+	Section            string  `json:"section"`
+	Speaker            string  `json:"speaker"`
+	Attachment         string  `json:"attachment"`
+	DetectedLanguage   string  `json:"detected_language"`
+	LanguageConfidence float64 `json:"language_confidence"`
+	GroupID            int64   `json:"group_id"`
+	Fingerprint        int64   `json:"fingerprint"`
+	DuplicateOf        string  `json:"duplicate_of"`
+	Tags               []int64 `json:"tags"`
+	Media              Media   `json:"media,omitempty"`
+	UpdatedAt          int64   `json:"updated_at"`
 }
 
 type Client struct {
@@ -81,19 +107,32 @@ func NewDBEntry(entry *feed.Entry) *DBEntry {
 	created := time.Now()
 
 	dbe := &DBEntry{
-		Language:   entry.Language,
-		Title:      entry.Title,
-		Url:        entry.Url,
-		Updated:    castTime(entry.Updated),
-		Published:  castTime(entry.Published),
-		Summary:    entry.Summary,
-		Content:    entry.Content,
-		Author:     entry.Author,
-		Number:     entry.Number,
-		ResourceID: entry.ResourceID,
-		Created:    castTime(&created),
-		Chunk:      entry.Chunk,
-		UpdatedAt:  castTime(&created),
+		Language:           entry.Language,
+		Title:              entry.Title,
+		Url:                entry.Url,
+		Updated:            castTime(entry.Updated),
+		Published:          castTime(entry.Published),
+		Summary:            entry.Summary,
+		Content:            entry.Content,
+		Author:             entry.Author,
+		Number:             entry.Number,
+		ResourceID:         entry.ResourceID,
+		Created:            castTime(&created),
+		Chunk:              entry.Chunk,
+		ChunkTotal:         entry.ChunkTotal,
+		ChunkOffset:        entry.ChunkOffset,
+		Overlap:            entry.Overlap,
+		Section:            entry.Section,
+		Speaker:            entry.Speaker,
+		Attachment:         entry.Attachment,
+		DetectedLanguage:   entry.DetectedLanguage,
+		LanguageConfidence: entry.LanguageConfidence,
+		GroupID:            entry.GroupID,
+		Fingerprint:        entry.Fingerprint,
+		DuplicateOf:        entry.DuplicateOf,
+		Tags:               entry.Tags,
+		Media:              entry.Media,
+		UpdatedAt:          castTime(&created),
 	}
 
 	return dbe
@@ -109,8 +148,8 @@ func castTime(value *time.Time) int64 {
 	}
 }
 
-func New(tbl string) (*Client, error) {
-	// Initialize ApiClient
+// newAPIClient создает клиент HTTP API мантикоры
+func newAPIClient() *openapiclient.APIClient {
 	configuration := openapiclient.NewConfiguration()
 	configuration.Servers = openapiclient.ServerConfigurations{
 		{
@@ -119,7 +158,12 @@ func New(tbl string) (*Client, error) {
 			Description: "Default Manticore Search HTTP",
 		},
 	}
-	apiClient := openapiclient.NewAPIClient(configuration)
+	return openapiclient.NewAPIClient(configuration)
+}
+
+func New(tbl string) (*Client, error) {
+	// Initialize ApiClient
+	apiClient := newAPIClient()
 
 	query := fmt.Sprintf(`show tables like '%v'`, tbl)
 
@@ -154,6 +198,12 @@ func New(tbl string) (*Client, error) {
 					if err != nil {
 						return nil, err
 					}
+				} else {
+					// Таблица существует, добавляем недостающие колонки
+					err := migrateTable(apiClient, tbl)
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 		}
@@ -170,7 +220,7 @@ func New(tbl string) (*Client, error) {
 
 func createTable(apiClient *openapiclient.APIClient, tbl string) error {
 
-	query := fmt.Sprintf(`create table %v(language string, url string, title text, summary text, content text, published timestamp, updated timestamp, author string, number string, resource_id int, created timestamp, updated_at timestamp, chunk int) min_infix_len='3' index_exact_words='1' morphology='stem_en, stem_ru, libstemmer_de, libstemmer_fr, libstemmer_es, libstemmer_pt' index_sp='1'`, tbl)
+	query := fmt.Sprintf(`create table %v(language string, url string, title text, summary text, content text, published timestamp, updated timestamp, author string, number string, resource_id int, created timestamp, updated_at timestamp, chunk int, chunk_total int, chunk_offset int, overlap int, section string, speaker string, media json, attachment string, detected_language string, language_confidence float, group_id bigint, fingerprint bigint, duplicate_of string, tags multi64) min_infix_len='3' index_exact_words='1' morphology='stem_en, stem_ru, libstemmer_de, libstemmer_fr, libstemmer_es, libstemmer_pt' index_sp='1'`, tbl)
 
 	sqlRequest := apiClient.UtilsAPI.Sql(context.Background()).Body(query)
 	_, _, err := apiClient.UtilsAPI.SqlExecute(sqlRequest)
@@ -230,19 +280,32 @@ func (c *Client) Insert(ctx context.Context, entry *feed.Entry) (*int64, error)
 func (c *Client) Update(ctx context.Context, entry *feed.Entry) error {
 
 	dbe := &DBEntry{
-		Language:   entry.Language,
-		Title:      entry.Title,
-		Url:        entry.Url,
-		Updated:    castTime(entry.Updated),
-		Published:  castTime(entry.Published),
-		Summary:    entry.Summary,
-		Content:    entry.Content,
-		Author:     entry.Author,
-		Number:     entry.Number,
-		ResourceID: entry.ResourceID,
-		Created:    castTime(entry.Created),
-		Chunk:      entry.Chunk,
-		UpdatedAt:  castTime(entry.UpdatedAt),
+		Language:           entry.Language,
+		Title:              entry.Title,
+		Url:                entry.Url,
+		Updated:            castTime(entry.Updated),
+		Published:          castTime(entry.Published),
+		Summary:            entry.Summary,
+		Content:            entry.Content,
+		Author:             entry.Author,
+		Number:             entry.Number,
+		ResourceID:         entry.ResourceID,
+		Created:            castTime(entry.Created),
+		Chunk:              entry.Chunk,
+		ChunkTotal:         entry.ChunkTotal,
+		ChunkOffset:        entry.ChunkOffset,
+		Overlap:            entry.Overlap,
+		Section:            entry.Section,
+		Speaker:            entry.Speaker,
+		Attachment:         entry.Attachment,
+		DetectedLanguage:   entry.DetectedLanguage,
+		LanguageConfidence: entry.LanguageConfidence,
+		GroupID:            entry.GroupID,
+		Fingerprint:        entry.Fingerprint,
+		DuplicateOf:        entry.DuplicateOf,
+		Tags:               entry.Tags,
+		Media:              entry.Media,
+		UpdatedAt:          castTime(entry.UpdatedAt),
 	}
 
 	//marshal into JSON buffer
@@ -352,20 +415,33 @@ func (c *Client) FindByUrl(ctx context.Context, url string) (*feed.Entry, error)
 	updatedAt := time.Unix(dbe.UpdatedAt, 0)
 
 	ent := &feed.Entry{
-		ID:         id,
-		Language:   dbe.Language,
-		Title:      dbe.Title,
-		Url:        dbe.Url,
-		Updated:    &updated,
-		Published:  &published,
-		Summary:    dbe.Summary,
-		Content:    dbe.Content,
-		Author:     dbe.Author,
-		Number:     dbe.Number,
-		ResourceID: dbe.ResourceID,
-		Created:    &created,
-		Chunk:      dbe.Chunk,
-		UpdatedAt:  &updatedAt,
+		ID:                 id,
+		Language:           dbe.Language,
+		Title:              dbe.Title,
+		Url:                dbe.Url,
+		Updated:            &updated,
+		Published:          &published,
+		Summary:            dbe.Summary,
+		Content:            dbe.Content,
+		Author:             dbe.Author,
+		Number:             dbe.Number,
+		ResourceID:         dbe.ResourceID,
+		Created:            &created,
+		Chunk:              dbe.Chunk,
+		ChunkTotal:         dbe.ChunkTotal,
+		ChunkOffset:        dbe.ChunkOffset,
+		Overlap:            dbe.Overlap,
+		Section:            dbe.Section,
+		Speaker:            dbe.Speaker,
+		Attachment:         dbe.Attachment,
+		DetectedLanguage:   dbe.DetectedLanguage,
+		LanguageConfidence: dbe.LanguageConfidence,
+		GroupID:            dbe.GroupID,
+		Fingerprint:        dbe.Fingerprint,
+		DuplicateOf:        dbe.DuplicateOf,
+		Tags:               dbe.Tags,
+		Media:              dbe.Media,
+		UpdatedAt:          &updatedAt,
 	}
 
 	return ent, nil
@@ -441,20 +517,33 @@ func (c *Client) FindAllByUrl(ctx context.Context, url string) ([]feed.Entry, er
 		updatedAt := time.Unix(dbe.UpdatedAt, 0)
 
 		ent := &feed.Entry{
-			ID:         &id,
-			Language:   dbe.Language,
-			Title:      dbe.Title,
-			Url:        dbe.Url,
-			Updated:    &updated,
-			Published:  &published,
-			Summary:    dbe.Summary,
-			Content:    dbe.Content,
-			Author:     dbe.Author,
-			Number:     dbe.Number,
-			ResourceID: dbe.ResourceID,
-			Created:    &created,
-			Chunk:      dbe.Chunk,
-			UpdatedAt:  &updatedAt,
+			ID:                 &id,
+			Language:           dbe.Language,
+			Title:              dbe.Title,
+			Url:                dbe.Url,
+			Updated:            &updated,
+			Published:          &published,
+			Summary:            dbe.Summary,
+			Content:            dbe.Content,
+			Author:             dbe.Author,
+			Number:             dbe.Number,
+			ResourceID:         dbe.ResourceID,
+			Created:            &created,
+			Chunk:              dbe.Chunk,
+			ChunkTotal:         dbe.ChunkTotal,
+			ChunkOffset:        dbe.ChunkOffset,
+			Overlap:            dbe.Overlap,
+			Section:            dbe.Section,
+			Speaker:            dbe.Speaker,
+			Attachment:         dbe.Attachment,
+			DetectedLanguage:   dbe.DetectedLanguage,
+			LanguageConfidence: dbe.LanguageConfidence,
+			GroupID:            dbe.GroupID,
+			Fingerprint:        dbe.Fingerprint,
+			DuplicateOf:        dbe.DuplicateOf,
+			Tags:               dbe.Tags,
+			Media:              dbe.Media,
+			UpdatedAt:          &updatedAt,
 		}
 
 		entries = append(entries, *ent)
@@ -561,19 +650,32 @@ func (c *Client) FindAll(ctx context.Context, limit int) (chan feed.Entry, error
 				source := hit.Source
 
 				dbe := &DBEntry{
-					Language:   source.Language,
-					Title:      source.Title,
-					Url:        source.Url,
-					Updated:    source.Updated,
-					Published:  source.Published,
-					Summary:    source.Summary,
-					Content:    source.Content,
-					Author:     source.Author,
-					Number:     source.Number,
-					ResourceID: source.ResourceID,
-					Created:    source.Created,
-					Chunk:      source.Chunk,
-					UpdatedAt:  source.UpdatedAt,
+					Language:           source.Language,
+					Title:              source.Title,
+					Url:                source.Url,
+					Updated:            source.Updated,
+					Published:          source.Published,
+					Summary:            source.Summary,
+					Content:            source.Content,
+					Author:             source.Author,
+					Number:             source.Number,
+					ResourceID:         source.ResourceID,
+					Created:            source.Created,
+					Chunk:              source.Chunk,
+					ChunkTotal:         source.ChunkTotal,
+					ChunkOffset:        source.ChunkOffset,
+					Overlap:            source.Overlap,
+					Section:            source.Section,
+					Speaker:            source.Speaker,
+					Attachment:         source.Attachment,
+					DetectedLanguage:   source.DetectedLanguage,
+					LanguageConfidence: source.LanguageConfidence,
+					GroupID:            source.GroupID,
+					Fingerprint:        source.Fingerprint,
+					DuplicateOf:        source.DuplicateOf,
+					Tags:               source.Tags,
+					Media:              source.Media,
+					UpdatedAt:          source.UpdatedAt,
 				}
 
 				updated := time.Unix(dbe.Updated, 0)
@@ -582,20 +684,33 @@ func (c *Client) FindAll(ctx context.Context, limit int) (chan feed.Entry, error
 				updatedAt := time.Unix(dbe.UpdatedAt, 0)
 
 				chout <- feed.Entry{
-					ID:         &id,
-					Language:   dbe.Language,
-					Title:      dbe.Title,
-					Url:        dbe.Url,
-					Updated:    &updated,
-					Published:  &published,
-					Summary:    dbe.Summary,
-					Content:    dbe.Content,
-					Author:     dbe.Author,
-					Number:     dbe.Number,
-					ResourceID: dbe.ResourceID,
-					Created:    &created,
-					Chunk:      dbe.Chunk,
-					UpdatedAt:  &updatedAt,
+					ID:                 &id,
+					Language:           dbe.Language,
+					Title:              dbe.Title,
+					Url:                dbe.Url,
+					Updated:            &updated,
+					Published:          &published,
+					Summary:            dbe.Summary,
+					Content:            dbe.Content,
+					Author:             dbe.Author,
+					Number:             dbe.Number,
+					ResourceID:         dbe.ResourceID,
+					Created:            &created,
+					Chunk:              dbe.Chunk,
+					ChunkTotal:         dbe.ChunkTotal,
+					ChunkOffset:        dbe.ChunkOffset,
+					Overlap:            dbe.Overlap,
+					Section:            dbe.Section,
+					Speaker:            dbe.Speaker,
+					Attachment:         dbe.Attachment,
+					DetectedLanguage:   dbe.DetectedLanguage,
+					LanguageConfidence: dbe.LanguageConfidence,
+					GroupID:            dbe.GroupID,
+					Fingerprint:        dbe.Fingerprint,
+					DuplicateOf:        dbe.DuplicateOf,
+					Tags:               dbe.Tags,
+					Media:              dbe.Media,
+					UpdatedAt:          &updatedAt,
 				}
 			}
 
@@ -0,0 +1,33 @@
+package manticore
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+)
+
+// Media список медиа записи, хранится в json атрибуте media.
+// Мантикора может вернуть json атрибут как массив или как строку с json,
+// поэтому при чтении поддерживаются оба варианта.
+type Media []feed.Media
+
+func (m *Media) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if strings.TrimSpace(s) == "" {
+			*m = nil
+			return nil
+		}
+		data = []byte(s)
+	}
+
+	var list []feed.Media
+	if err := json.Unmarshal(data, &list); err != nil {
+		// Пустой json атрибут мантикора возвращает как объект {}
+		*m = nil
+		return nil
+	}
+	*m = list
+	return nil
+}
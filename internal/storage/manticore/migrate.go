@@ -0,0 +1,66 @@
+package manticore
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	openapiclient "github.com/manticoresoftware/manticoresearch-go"
+)
+
+// column колонка таблицы, добавленная после создания схемы
+type column struct {
+	name string
+	typ  string
+}
+
+// addedColumns колонки, которые добавляются в ранее созданные таблицы
+var addedColumns = []column{
+	{"chunk_total", "int"},
+	{"chunk_offset", "int"},
+	{"overlap", "int"},
+	{"section", "string"},
+	{"speaker", "string"},
+	{"media", "json"},
+	{"attachment", "string"},
+	{"detected_language", "string"},
+	{"language_confidence", "float"},
+	{"group_id", "bigint"},
+	{"fingerprint", "bigint"},
+	{"duplicate_of", "string"},
+	{"tags", "multi64"},
+}
+
+// migrateTable добавляет в существующую таблицу tbl колонки, которых в ней нет
+func migrateTable(apiClient *openapiclient.APIClient, tbl string) error {
+	resp, _, err := apiClient.UtilsAPI.Sql(context.Background()).Body(fmt.Sprintf("describe %v", tbl)).RawResponse(true).Execute()
+	if err != nil {
+		return fmt.Errorf("error when calling `UtilsAPI.Sql` describe %v: %v", tbl, err)
+	}
+
+	fields := make(map[string]bool)
+	if len(resp) > 0 {
+		if data, ok := resp[0]["data"].([]interface{}); ok {
+			for _, rows := range data {
+				row := rows.(map[string]interface{})
+				if name, ok := row["Field"].(string); ok {
+					fields[name] = true
+				}
+			}
+		}
+	}
+
+	for _, col := range addedColumns {
+		if fields[col.name] {
+			continue
+		}
+		query := fmt.Sprintf("alter table %v add column %v %v", tbl, col.name, col.typ)
+		_, _, err := apiClient.UtilsAPI.Sql(context.Background()).Body(query).RawResponse(true).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to add column %v to %v: %v", col.name, tbl, err)
+		}
+		log.Printf("added column %v %v to table %v", col.name, col.typ, tbl)
+	}
+
+	return nil
+}
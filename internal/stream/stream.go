@@ -0,0 +1,248 @@
+// Package stream транслирует клиентам новые и обновленные записи из журнала событий
+// через Server-Sent Events или WebSocket
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/events"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// batchSize сколько событий читается из журнала за раз
+	batchSize = 100
+	// heartbeat интервал пустых сообщений, которые не дают прокси закрыть соединение
+	heartbeat = 30 * time.Second
+	// retry через сколько миллисекунд EventSource переподключается после обрыва соединения
+	retry = 3000
+)
+
+// Filter условия отбора событий для клиента. Пустой список означает любое значение.
+type Filter struct {
+	Resources []int
+	Languages []string
+}
+
+// ParseFilter читает фильтр из параметров запроса resource_id и language.
+// Значения можно передать через запятую или повторив параметр.
+func ParseFilter(q url.Values) (Filter, error) {
+	var f Filter
+	for _, v := range splitValues(q["resource_id"]) {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid resource_id %q", v)
+		}
+		f.Resources = append(f.Resources, id)
+	}
+	f.Languages = splitValues(q["language"])
+	return f, nil
+}
+
+// Match сообщает, что событие о сохранении или обновлении записи подходит под фильтр
+func (f Filter) Match(ev events.Event) bool {
+	if ev.Type != events.EntryCreated && ev.Type != events.EntryUpdated {
+		return false
+	}
+	if len(f.Resources) > 0 && !containsInt(f.Resources, ev.Entry.ResourceID) {
+		return false
+	}
+	if len(f.Languages) > 0 && !containsString(f.Languages, ev.Entry.Language) {
+		return false
+	}
+	return true
+}
+
+// Handler отдает события журнала outbox по адресу /api/stream. Запрос с заголовком Upgrade: websocket
+// переводится на WebSocket, остальные получают поток text/event-stream. Клиент продолжает чтение
+// с события, следующего за Last-Event-ID (заголовок или параметр last_event_id), иначе получает только новые события.
+type Handler struct {
+	outbox *events.Outbox
+	poll   time.Duration
+}
+
+// NewHandler создает обработчик, который проверяет появление новых событий в журнале каждые poll
+func NewHandler(outbox *events.Outbox, poll time.Duration) *Handler {
+	return &Handler{outbox: outbox, poll: poll}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	s, err := h.outbox.Stream(lastID)
+	if err != nil {
+		log.Printf("stream: failed to open outbox: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		// Рукопожатие без проверки Origin: поток публичный и только для чтения
+		ws := websocket.Server{Handler: func(conn *websocket.Conn) {
+			h.serveWebSocket(conn, s, filter)
+		}}
+		ws.ServeHTTP(unwrap(w), r)
+		return
+	}
+	h.serveSSE(w, r, s, filter)
+}
+
+// serveSSE отправляет события в формате Server-Sent Events, пока клиент не закроет соединение
+func (h *Handler) serveSSE(w http.ResponseWriter, r *http.Request, s *events.Stream, filter Filter) {
+	rc := http.NewResponseController(w)
+	// Поток не ограничен по времени, снимаем тайм-аут записи сервера
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("stream: failed to reset write deadline: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retry)
+	rc.Flush()
+
+	h.run(r.Context().Done(), s, filter, func(ev *events.Event) error {
+		if ev == nil {
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			if err == nil {
+				err = rc.Flush()
+			}
+			return err
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
+
+// serveWebSocket отправляет события текстовыми сообщениями JSON, пока клиент не закроет соединение
+func (h *Handler) serveWebSocket(conn *websocket.Conn, s *events.Stream, filter Filter) {
+	defer conn.Close()
+	conn.SetDeadline(time.Time{})
+
+	// Сообщения клиента не ожидаются, чтение нужно только чтобы заметить закрытие соединения
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var msg []byte
+		for websocket.Message.Receive(conn, &msg) == nil {
+		}
+	}()
+
+	h.run(done, s, filter, func(ev *events.Event) error {
+		if ev == nil {
+			return websocket.Message.Send(conn, "")
+		}
+		return websocket.JSON.Send(conn, ev)
+	})
+}
+
+// run читает события журнала и передает подходящие под фильтр в send, пока не закрыт done
+// или send не вернул ошибку. Для поддержания соединения send вызывается с nil.
+func (h *Handler) run(done <-chan struct{}, s *events.Stream, filter Filter, send func(ev *events.Event) error) {
+	ticker := time.NewTicker(h.poll)
+	defer ticker.Stop()
+	idle := time.Now()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			evs, err := s.Next(batchSize)
+			if err != nil {
+				log.Printf("stream: failed to read outbox: %v", err)
+				return
+			}
+			for i := range evs {
+				if !filter.Match(evs[i]) {
+					continue
+				}
+				if err := send(&evs[i]); err != nil {
+					return
+				}
+				idle = time.Now()
+			}
+			if len(evs) < batchSize {
+				break
+			}
+		}
+
+		if time.Since(idle) >= heartbeat {
+			if err := send(nil); err != nil {
+				return
+			}
+			idle = time.Now()
+		}
+	}
+}
+
+// unwrap возвращает исходный ResponseWriter сервера, который поддерживает перехват соединения
+func unwrap(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
+
+func splitValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package tagger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// Типы сущностей словаря
+const (
+	TypeCountry      = "country"
+	TypePerson       = "person"
+	TypeOrganization = "organization"
+)
+
+// slugRe допустимое короткое имя, используется в имени файла фида
+var slugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Entity сущность словаря: страна, должностное лицо или организация
+// и ее названия на русском и английском языках
+type Entity struct {
+	ID   int64    `yaml:"id"`   // Идентификатор тега в мантикоре
+	Slug string   `yaml:"slug"` // Короткое имя тега для фильтров и адресов фидов
+	Type string   `yaml:"type"` // country, person или organization
+	Name string   `yaml:"name"` // Основное название
+	Ru   []string `yaml:"ru"`   // Названия и варианты на русском языке
+	En   []string `yaml:"en"`   // Названия и варианты на английском языке
+	Feed bool     `yaml:"feed"` // Формировать отдельный RSS-фид записей с тегом
+}
+
+// Dictionary словарь сущностей
+type Dictionary struct {
+	Entities []Entity `yaml:"entities"`
+}
+
+// LoadDictionary читает словарь сущностей из yaml файла
+func LoadDictionary(path string) (*Dictionary, error) {
+	var d Dictionary
+	if err := cleanenv.ReadConfig(path, &d); err != nil {
+		return nil, fmt.Errorf("failed to read dictionary %v: %v", path, err)
+	}
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("dictionary %v: %w", path, err)
+	}
+	return &d, nil
+}
+
+// Validate проверяет уникальность и формат идентификаторов и коротких имен сущностей
+func (d *Dictionary) Validate() error {
+	var errs []error
+	ids := make(map[int64]bool)
+	slugs := make(map[string]bool)
+
+	for n, e := range d.Entities {
+		if e.ID <= 0 {
+			errs = append(errs, fmt.Errorf("entities[%d]: id must be positive", n))
+		} else if ids[e.ID] {
+			errs = append(errs, fmt.Errorf("entities[%d]: duplicate id %d", n, e.ID))
+		}
+		if e.Slug == "" {
+			errs = append(errs, fmt.Errorf("entities[%d]: slug is not set", n))
+		} else if slugs[e.Slug] {
+			errs = append(errs, fmt.Errorf("entities[%d]: duplicate slug %q", n, e.Slug))
+		} else if !slugRe.MatchString(e.Slug) {
+			errs = append(errs, fmt.Errorf("entities[%d]: slug %q must contain only a-z, 0-9 and -", n, e.Slug))
+		}
+		switch e.Type {
+		case TypeCountry, TypePerson, TypeOrganization:
+		default:
+			errs = append(errs, fmt.Errorf("entities[%d]: unknown type %q", n, e.Type))
+		}
+		ids[e.ID] = true
+		slugs[e.Slug] = true
+	}
+	return errors.Join(errs...)
+}
+
+// BySlug возвращает сущность по короткому имени
+func (d *Dictionary) BySlug(slug string) (Entity, bool) {
+	for _, e := range d.Entities {
+		if e.Slug == slug {
+			return e, true
+		}
+	}
+	return Entity{}, false
+}
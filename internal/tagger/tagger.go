@@ -0,0 +1,167 @@
+// Package tagger отмечает в записях упоминания стран, должностных лиц и организаций
+// по словарю названий
+package tagger
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minStem минимальная длина основы русского слова, более короткие слова сравниваются целиком
+const minStem = 4
+
+// ruEndings окончания русских существительных, прилагательных и фамилий,
+// с которыми слово текста считается формой слова словаря
+var ruEndings = map[string]bool{
+	"": true, "а": true, "я": true, "у": true, "ю": true, "е": true, "и": true, "ы": true, "о": true,
+	"ой": true, "ей": true, "ом": true, "ем": true, "ам": true, "ям": true, "ах": true, "ях": true,
+	"ами": true, "ями": true, "ов": true, "ев": true, "ый": true, "ий": true, "ая": true, "яя": true,
+	"ое": true, "ее": true, "ые": true, "ие": true, "ую": true, "юю": true, "ого": true, "его": true,
+	"ому": true, "ему": true, "ым": true, "им": true, "ых": true, "их": true, "ою": true, "ею": true,
+	"ь": true, "ью": true, "ьи": true, "ья": true, "й": true, "ыми": true, "ими": true,
+}
+
+// word матчер слова названия
+type word struct {
+	exact string // аббревиатура, сравнивается с учетом регистра
+	lower string // слово в нижнем регистре
+	ru    bool   // русское слово, совпадает с формами слова
+}
+
+type pattern struct {
+	entity int64
+	words  []word
+}
+
+// Tagger находит упоминания сущностей словаря в тексте
+type Tagger struct {
+	dict     *Dictionary
+	patterns map[string][]pattern
+}
+
+// New создает Tagger по словарю
+func New(dict *Dictionary) *Tagger {
+	t := &Tagger{
+		dict:     dict,
+		patterns: make(map[string][]pattern),
+	}
+	for _, e := range dict.Entities {
+		for _, name := range e.Ru {
+			if p, ok := compile(e.ID, name, true); ok {
+				t.patterns["ru"] = append(t.patterns["ru"], p)
+			}
+		}
+		for _, name := range e.En {
+			if p, ok := compile(e.ID, name, false); ok {
+				t.patterns["en"] = append(t.patterns["en"], p)
+			}
+		}
+	}
+	return t
+}
+
+// Dictionary возвращает словарь сущностей
+func (t *Tagger) Dictionary() *Dictionary {
+	return t.dict
+}
+
+func compile(id int64, name string, ru bool) (pattern, bool) {
+	p := pattern{entity: id}
+	for _, w := range tokenize(name) {
+		if isAcronym(w) {
+			p.words = append(p.words, word{exact: w})
+			continue
+		}
+		p.words = append(p.words, word{lower: normalize(w), ru: ru})
+	}
+	return p, len(p.words) > 0
+}
+
+func normalize(w string) string {
+	return strings.ReplaceAll(strings.ToLower(w), "ё", "е")
+}
+
+// match сравнивает слово текста со словом названия. Русские слова совпадают, если у них
+// общая основа не короче minStem символов, а остатки являются окончаниями.
+func (w word) match(token string) bool {
+	if w.exact != "" {
+		return token == w.exact
+	}
+	lower := normalize(token)
+	if lower == w.lower {
+		return true
+	}
+	if !w.ru {
+		return false
+	}
+
+	runes := []rune(w.lower)
+	for k := 0; k <= 3 && len(runes)-k >= minStem; k++ {
+		stem := string(runes[:len(runes)-k])
+		if !ruEndings[string(runes[len(runes)-k:])] || !strings.HasPrefix(lower, stem) {
+			continue
+		}
+		if ruEndings[lower[len(stem):]] {
+			return true
+		}
+	}
+	return false
+}
+
+// isAcronym сообщает, является ли слово аббревиатурой из заглавных букв
+func isAcronym(w string) bool {
+	letters := 0
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+// tokenize разбивает текст на слова из букв и цифр
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tag возвращает идентификаторы сущностей, упомянутых в тексте на языке lang, по возрастанию.
+// Для языков, кроме ru и en, сущности не определяются.
+func (t *Tagger) Tag(text string, lang string) []int64 {
+	patterns := t.patterns[lang]
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	tokens := tokenize(text)
+	found := make(map[int64]bool)
+	for i := range tokens {
+		for _, p := range patterns {
+			if found[p.entity] || i+len(p.words) > len(tokens) {
+				continue
+			}
+			matched := true
+			for j, w := range p.words {
+				if !w.match(tokens[i+j]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				found[p.entity] = true
+			}
+		}
+	}
+
+	var tags []int64
+	for id := range found {
+		tags = append(tags, id)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
@@ -0,0 +1,115 @@
+package telegram
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/sentence"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+)
+
+const (
+	// MaxMessageLength максимальная длина текста сообщения в символах UTF-16 после разбора разметки
+	MaxMessageLength = 4096
+	// maxTitleLength максимальная длина заголовка, чтобы в сообщении оставалось место для анонса
+	maxTitleLength = 1024
+)
+
+// sourceNames названия источников по идентификаторам ресурсов
+var sourceNames = map[int]string{
+	1: "Кремль",
+	2: "МИД России",
+	3: "Минобороны России",
+}
+
+// Format возвращает текст сообщения о записи в разметке HTML: заголовок, анонс не длиннее summaryLength символов,
+// источник и ссылку. Если у записи нет анонса, используются первые предложения контента.
+// Текст сообщения не превышает MaxMessageLength.
+func Format(e feed.Entry, summaryLength int) string {
+	title := truncate(plainText(e.Title), maxTitleLength)
+
+	summary := plainText(e.Summary)
+	if summary == "" {
+		summary = plainText(e.Content)
+	}
+	if summaryLength > 0 {
+		summary = sentence.First(summary, e.Language, summaryLength)
+	} else {
+		summary = ""
+	}
+
+	source := sourceNames[e.ResourceID]
+	if source == "" {
+		if u, err := url.Parse(e.Url); err == nil {
+			source = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+
+	// Анонс сокращается, если сообщение не помещается в лимит, остальные части сообщения не сокращаются
+	fixed := textLength(title) + textLength(source) + textLength(e.Url) + len("\n\n\n\n\n")
+	summary = truncate(summary, MaxMessageLength-fixed)
+
+	var b strings.Builder
+	if title != "" {
+		b.WriteString("<b>" + html.EscapeString(title) + "</b>\n\n")
+	}
+	if summary != "" {
+		b.WriteString(html.EscapeString(summary) + "\n\n")
+	}
+	if source != "" {
+		b.WriteString("<i>" + html.EscapeString(source) + "</i>\n")
+	}
+	b.WriteString(html.EscapeString(e.Url))
+	return b.String()
+}
+
+// plainText возвращает текст без разметки HTML с одиночными пробелами между словами
+func plainText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(striphtml.StripHtmlTags(strings.ReplaceAll(s, "<", " <")))), " ")
+}
+
+// textLength длина текста в символах UTF-16, как ее считает Telegram
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeLength(r)
+	}
+	return n
+}
+
+// runeLength длина символа в UTF-16: символы вне базовой плоскости занимают две позиции
+func runeLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// truncate сокращает текст до max символов UTF-16 по границе слова и добавляет многоточие
+func truncate(s string, max int) string {
+	if textLength(s) <= max {
+		return s
+	}
+	if max <= 1 {
+		return ""
+	}
+
+	// Одно место оставляем для многоточия
+	n, cut, space := 0, len(s), 0
+	for i, r := range s {
+		if n+runeLength(r) > max-1 {
+			cut = i
+			break
+		}
+		n += runeLength(r)
+		if r == ' ' {
+			space = i
+		}
+	}
+	if space > 0 {
+		cut = space
+	}
+	return strings.TrimRight(s[:cut], " ,;:-") + "…"
+}
@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+)
+
+// visibleLength длина сообщения в символах UTF-16 после разбора разметки HTML, как ее считает Telegram
+func visibleLength(text string) int {
+	return len(utf16.Encode([]rune(html.UnescapeString(striphtml.StripHtmlTags(text)))))
+}
+
+func TestFormatTruncatesToLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary string
+	}{
+		{"ascii", strings.Repeat("word ", 3000)},
+		{"cyrillic", strings.Repeat("слово ", 3000)},
+		{"astral", strings.Repeat("🇷🇺 ", 3000)},
+		{"escaped", strings.Repeat("a&b<c> ", 3000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := feed.Entry{
+				Title:      "Заголовок",
+				Url:        "http://kremlin.ru/events/president/news/1",
+				Summary:    html.EscapeString(tt.summary),
+				ResourceID: 1,
+				Language:   "ru",
+			}
+			text := Format(e, 100000)
+			if n := visibleLength(text); n > MaxMessageLength {
+				t.Errorf("message length = %d, want at most %d", n, MaxMessageLength)
+			}
+			if n := visibleLength(text); n < MaxMessageLength-10 {
+				t.Errorf("message length = %d, summary is truncated too much", n)
+			}
+			if !strings.Contains(text, "…") {
+				t.Error("truncated summary has no ellipsis")
+			}
+			if !strings.HasSuffix(text, e.Url) {
+				t.Errorf("message does not end with the entry url: %q", text[len(text)-100:])
+			}
+		})
+	}
+}
+
+func TestFormatLongTitle(t *testing.T) {
+	e := feed.Entry{Title: strings.Repeat("🇷🇺", 2000), Url: "https://mid.ru/ru/1", ResourceID: 2}
+	text := Format(e, 600)
+	title := text[:strings.Index(text, "\n")]
+	if n := visibleLength(title); n > maxTitleLength {
+		t.Errorf("title length = %d, want at most %d", n, maxTitleLength)
+	}
+	if !strings.Contains(text, "<i>МИД России</i>") {
+		t.Errorf("message has no source name: %q", text)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"one two three", 10, "one two…"},
+		{"оченьдлинноеслово", 6, "очень…"},
+		{"🇷🇺🇷🇺🇷🇺", 5, "🇷🇺…"},
+		{"🇷🇺🇷🇺🇷🇺", 4, "🇷…"},
+		{"😀😀😀", 4, "😀…"},
+		{"text", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+		if got := truncate(tt.in, tt.max); textLength(got) > tt.max {
+			t.Errorf("truncate(%q, %d) length = %d", tt.in, tt.max, textLength(got))
+		}
+	}
+}
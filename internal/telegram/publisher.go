@@ -0,0 +1,143 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// maxRetries сколько раз сообщение повторно отправляется после ответа 429 Too Many Requests
+const maxRetries = 3
+
+// sent отметка об отправленном сообщении в журнале публикаций
+type sent struct {
+	MessageID int64     `json:"message_id"`
+	Time      time.Time `json:"time"`
+}
+
+// Publisher публикует новые записи в чаты из конфигурации. Сообщения в один чат отправляются не чаще
+// одного в telegram.interval. Отправленные сообщения отмечаются в журнале публикаций, поэтому повторная
+// доставка события, например, после перезапуска службы, не приводит к повторной публикации записи.
+type Publisher struct {
+	client  *Client
+	cfg     config.Telegram
+	metrics *metrics.Metrics
+
+	mu   sync.Mutex
+	sent map[string]sent      // Отправленные сообщения по ключу chat_id и адресу записи
+	last map[string]time.Time // Время последнего сообщения в чат
+}
+
+// NewPublisher создает Publisher и загружает журнал публикаций telegram.log
+func NewPublisher(cfg config.Telegram, m *metrics.Metrics) (*Publisher, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Log), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create telegram log dir: %v", err)
+	}
+
+	p := &Publisher{
+		client:  NewClient(cfg.BaseURL, cfg.Token, cfg.Timeout),
+		cfg:     cfg,
+		metrics: m,
+		sent:    make(map[string]sent),
+		last:    make(map[string]time.Time),
+	}
+	if _, err := jsonfile.Read(cfg.Log, &p.sent); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Publish отправляет запись во все подходящие чаты, в которые она еще не отправлялась.
+// Ошибка возвращается, если отправку стоит повторить позже; чаты, в которые запись уже отправлена, при повторе пропускаются.
+// Сообщения, которые Bot API отклонил, например, из-за неверного chat_id, не повторяются.
+func (p *Publisher) Publish(ctx context.Context, e feed.Entry) error {
+	if p.cfg.MaxAge > 0 && e.Published != nil && time.Since(*e.Published) > p.cfg.MaxAge {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var text string
+	for _, chat := range p.cfg.Chats {
+		if !chat.Match(e.ResourceID, e.Language) {
+			continue
+		}
+		key := chat.ChatID + " " + e.Url
+		if _, ok := p.sent[key]; ok {
+			continue
+		}
+		if text == "" {
+			text = Format(e, p.cfg.SummaryLength)
+		}
+
+		msg := Message{ChatID: chat.ChatID, Text: text, ParseMode: "HTML"}
+		if chat.DisablePreview {
+			msg.LinkPreviewOptions = &LinkPreviewOptions{IsDisabled: true}
+		}
+		id, err := p.send(ctx, msg)
+		if err != nil {
+			p.metrics.TelegramMessages.WithLabelValues(chat.ChatID, "failed").Inc()
+			var apiErr *Error
+			if errors.As(err, &apiErr) && !apiErr.Temporary() {
+				log.Printf("telegram: %v rejected %v: %v", chat.ChatID, e.Url, err)
+				continue
+			}
+			return err
+		}
+		p.metrics.TelegramMessages.WithLabelValues(chat.ChatID, "ok").Inc()
+		log.Printf("telegram: %v posted to %v", e.Url, chat.ChatID)
+
+		p.sent[key] = sent{MessageID: id, Time: time.Now()}
+		if err := p.save(); err != nil {
+			return fmt.Errorf("failed to save telegram log: %v", err)
+		}
+	}
+	return nil
+}
+
+// send отправляет сообщение с учетом интервала между сообщениями в чат и повторяет его,
+// если Bot API просит подождать
+func (p *Publisher) send(ctx context.Context, msg Message) (int64, error) {
+	wait := p.cfg.Interval - time.Since(p.last[msg.ChatID])
+	for attempt := 0; ; attempt++ {
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		id, err := p.client.SendMessage(ctx, msg)
+		p.last[msg.ChatID] = time.Now()
+
+		var apiErr *Error
+		if attempt < maxRetries && errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			log.Printf("telegram: rate limited by %v, retry after %v", msg.ChatID, apiErr.RetryAfter)
+			wait = apiErr.RetryAfter
+			continue
+		}
+		return id, err
+	}
+}
+
+// save записывает журнал публикаций без отметок старше telegram.retention
+func (p *Publisher) save() error {
+	for key, s := range p.sent {
+		if time.Since(s.Time) > p.cfg.Retention {
+			delete(p.sent, key)
+		}
+	}
+	return jsonfile.Write(p.cfg.Log, p.sent)
+}
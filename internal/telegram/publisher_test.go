@@ -0,0 +1,226 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// fakeAPI сервер Bot API, который сохраняет отправленные сообщения. Ответы replies
+// возвращаются по очереди, после них сообщения принимаются.
+type fakeAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []Message
+	times    []time.Time
+	replies  []string
+}
+
+func newFakeAPI(t *testing.T, replies ...string) *fakeAPI {
+	api := &fakeAPI{replies: replies}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			http.NotFound(w, r)
+			return
+		}
+		var m Message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.messages = append(api.messages, m)
+		api.times = append(api.times, time.Now())
+		if len(api.replies) > 0 {
+			reply := api.replies[0]
+			api.replies = api.replies[1:]
+			var r apiResponse
+			json.Unmarshal([]byte(reply), &r)
+			w.WriteHeader(r.ErrorCode)
+			fmt.Fprint(w, reply)
+			return
+		}
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, len(api.messages))
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *fakeAPI) sent() []Message {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]Message(nil), api.messages...)
+}
+
+func testConfig(api *fakeAPI, log string, chats ...config.TelegramChat) config.Telegram {
+	return config.Telegram{
+		Enabled:       true,
+		BaseURL:       api.URL,
+		Token:         "token",
+		Chats:         chats,
+		SummaryLength: 600,
+		Timeout:       5 * time.Second,
+		Log:           log,
+		Retention:     time.Hour,
+	}
+}
+
+func newTestPublisher(t *testing.T, cfg config.Telegram) *Publisher {
+	p, err := NewPublisher(cfg, metrics.NewMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+var testEntry = feed.Entry{
+	Title:      "Заявление для прессы",
+	Url:        "http://kremlin.ru/events/president/news/1",
+	Summary:    "Краткое содержание.",
+	ResourceID: 1,
+	Language:   "ru",
+}
+
+func TestPublishChatFilters(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := testConfig(api, filepath.Join(t.TempDir(), "sent.json"),
+		config.TelegramChat{ChatID: "@all"},
+		config.TelegramChat{ChatID: "@kremlin", Resources: []int{1}, DisablePreview: true},
+		config.TelegramChat{ChatID: "@mid", Resources: []int{2}},
+		config.TelegramChat{ChatID: "@en", Languages: []string{"en"}},
+		config.TelegramChat{ChatID: "@kremlin_ru", Resources: []int{1, 3}, Languages: []string{"ru", "de"}},
+	)
+	p := newTestPublisher(t, cfg)
+
+	if err := p.Publish(context.Background(), testEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	var chats []string
+	for _, m := range api.sent() {
+		chats = append(chats, m.ChatID)
+		if m.ParseMode != "HTML" || m.Text != Format(testEntry, cfg.SummaryLength) {
+			t.Errorf("%v: unexpected message %+v", m.ChatID, m)
+		}
+		if preview := m.LinkPreviewOptions != nil && m.LinkPreviewOptions.IsDisabled; preview != (m.ChatID == "@kremlin") {
+			t.Errorf("%v: preview disabled = %v", m.ChatID, preview)
+		}
+	}
+	if fmt.Sprint(chats) != "[@all @kremlin @kremlin_ru]" {
+		t.Errorf("messages sent to %v, want [@all @kremlin @kremlin_ru]", chats)
+	}
+}
+
+func TestPublishIdempotentAcrossReload(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := testConfig(api, filepath.Join(t.TempDir(), "telegram", "sent.json"), config.TelegramChat{ChatID: "@all"})
+
+	p := newTestPublisher(t, cfg)
+	for i := 0; i < 2; i++ {
+		if err := p.Publish(context.Background(), testEntry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(api.sent()); n != 1 {
+		t.Fatalf("messages sent = %d, want 1", n)
+	}
+
+	// Повторная доставка события после перезапуска службы
+	reloaded := newTestPublisher(t, cfg)
+	if err := reloaded.Publish(context.Background(), testEntry); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(api.sent()); n != 1 {
+		t.Errorf("messages sent after reload = %d, want 1", n)
+	}
+
+	// Новый чат получает запись, которая уже отправлена в другие чаты
+	cfg.Chats = append(cfg.Chats, config.TelegramChat{ChatID: "@new"})
+	if err := newTestPublisher(t, cfg).Publish(context.Background(), testEntry); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := api.sent(); len(msgs) != 2 || msgs[1].ChatID != "@new" {
+		t.Errorf("messages sent = %v, want one more to @new", msgs)
+	}
+}
+
+func TestPublishRetryAfter(t *testing.T) {
+	api := newFakeAPI(t, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+	p := newTestPublisher(t, testConfig(api, filepath.Join(t.TempDir(), "sent.json"), config.TelegramChat{ChatID: "@all"}))
+
+	if err := p.Publish(context.Background(), testEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.messages) != 2 {
+		t.Fatalf("requests = %d, want 2", len(api.messages))
+	}
+	if wait := api.times[1].Sub(api.times[0]); wait < time.Second {
+		t.Errorf("retried after %v, want at least retry_after 1s", wait)
+	}
+}
+
+func TestPublishRetryAfterCanceled(t *testing.T) {
+	api := newFakeAPI(t, `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":60}}`)
+	p := newTestPublisher(t, testConfig(api, filepath.Join(t.TempDir(), "sent.json"), config.TelegramChat{ChatID: "@all"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := p.Publish(ctx, testEntry); err == nil {
+		t.Fatal("Publish() error = nil, want context error")
+	}
+	if n := len(api.sent()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestPublishSkipsRejectedChat(t *testing.T) {
+	api := newFakeAPI(t, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`)
+	p := newTestPublisher(t, testConfig(api, filepath.Join(t.TempDir(), "sent.json"),
+		config.TelegramChat{ChatID: "@missing"},
+		config.TelegramChat{ChatID: "@all"},
+	))
+
+	if err := p.Publish(context.Background(), testEntry); err != nil {
+		t.Fatalf("Publish() error = %v, want rejected chat to be skipped", err)
+	}
+	if msgs := api.sent(); len(msgs) != 2 || msgs[1].ChatID != "@all" {
+		t.Errorf("messages sent = %v, want @missing and @all", msgs)
+	}
+}
+
+func TestPublishInterval(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := testConfig(api, filepath.Join(t.TempDir(), "sent.json"), config.TelegramChat{ChatID: "@all"})
+	cfg.Interval = 200 * time.Millisecond
+	p := newTestPublisher(t, cfg)
+
+	second := testEntry
+	second.Url += "2"
+	for _, e := range []feed.Entry{testEntry, second} {
+		if err := p.Publish(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if wait := api.times[1].Sub(api.times[0]); wait < cfg.Interval {
+		t.Errorf("messages sent %v apart, want at least %v", wait, cfg.Interval)
+	}
+}
@@ -0,0 +1,105 @@
+// Package telegram публикует новые записи в чаты и каналы через Telegram Bot API
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client клиент метода sendMessage Telegram Bot API
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient создает клиента Bot API по адресу baseURL, например, https://api.telegram.org
+// или адресу локального сервера для тестов
+func NewClient(baseURL string, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Message параметры метода sendMessage
+type Message struct {
+	ChatID             string              `json:"chat_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
+}
+
+// LinkPreviewOptions параметры предпросмотра ссылки в сообщении
+type LinkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+// Error ошибка, которую вернул Bot API
+type Error struct {
+	Code        int
+	Description string
+	RetryAfter  time.Duration // Через сколько можно повторить запрос, если превышен лимит сообщений
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+// Temporary сообщает, что запрос можно повторить позже
+func (e *Error) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	ErrorCode   int    `json:"error_code"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+	Parameters struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// SendMessage отправляет сообщение и возвращает его идентификатор в чате
+func (c *Client) SendMessage(ctx context.Context, m Message) (int64, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Ошибка содержит адрес запроса с токеном бота
+		return 0, fmt.Errorf("failed to send message to %v: %v", m.ChatID, strings.ReplaceAll(err.Error(), c.token, "***"))
+	}
+	defer resp.Body.Close()
+
+	var r apiResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&r); err != nil {
+		return 0, &Error{Code: resp.StatusCode, Description: fmt.Sprintf("invalid response: %v", err)}
+	}
+	if !r.OK {
+		code := r.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return 0, &Error{Code: code, Description: r.Description, RetryAfter: time.Duration(r.Parameters.RetryAfter) * time.Second}
+	}
+	return r.Result.MessageID, nil
+}
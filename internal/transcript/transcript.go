@@ -0,0 +1,170 @@
+// Package transcript выделяет реплики в стенограммах и брифингах:
+// абзацы, начинающиеся с метки говорящего «В.Путин: ...», метки роли «Вопрос: ...»
+// или выделенной жирным шрифтом метки «<b>Ведущий:</b> ...».
+package transcript
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Turn реплика стенограммы
+type Turn struct {
+	Speaker string `json:"speaker"`
+	Text    string `json:"text"`
+	Order   int    `json:"order"`
+}
+
+// initialsRe метка говорящего из инициалов и фамилии в начале абзаца: «В.Путин:», «М.В.Захарова:», «S.Lavrov:».
+// Метки другого вида, кроме ролей из roleRe, принимаются только выделенными жирным шрифтом,
+// иначе обычный текст «Например: ...» или «Справка: ...» считался бы репликой.
+var initialsRe = regexp.MustCompile(`^((?:\p{Lu}\.\s?){1,3}\p{Lu}[\p{L}\-]*\p{Ll})\s?:(?:\s|$)`)
+
+// roleRe метка роли в начале абзаца, которая принимается и без выделения жирным шрифтом
+var roleRe = regexp.MustCompile(`^(Вопрос|Ответ|Реплика|Question|Answer)\s?:(?:\s|$)`)
+
+// labelRe метка говорящего, выделенная жирным шрифтом: до четырех слов с заглавной буквы в начале
+var labelRe = regexp.MustCompile(`^[\p{Lu}][\p{L}.\-]*(?: [\p{L}.\-]+){0,3}$`)
+
+// maxSpeakerLen максимальная длина метки говорящего в символах
+const maxSpeakerLen = 40
+
+// blockTags теги, текст которых считается отдельным абзацем
+var blockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "blockquote": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// Label возвращает метку говорящего, с которой начинается html абзаца,
+// или пустую строку, если абзац не начинается с метки
+func Label(content string) string {
+	pars := paragraphs(content)
+	if len(pars) == 0 {
+		return ""
+	}
+	label, _ := pars[0].label()
+	return label
+}
+
+// Normalize приводит метку говорящего к единому виду: «В. В. Путин» -> «В.В.Путин»
+func Normalize(label string) string {
+	label = strings.Join(strings.Fields(label), " ")
+	return strings.ReplaceAll(label, ". ", ".")
+}
+
+// Parse выделяет реплики из html контента. Абзацы без метки говорящего
+// присоединяются к предыдущей реплике, абзацы до первой метки пропускаются.
+func Parse(content string) []Turn {
+	var turns []Turn
+
+	for _, par := range paragraphs(content) {
+		if label, text := par.label(); label != "" {
+			turns = append(turns, Turn{
+				Speaker: label,
+				Text:    text,
+				Order:   len(turns) + 1,
+			})
+			continue
+		}
+		if len(turns) > 0 {
+			turns[len(turns)-1].Text += "\n" + par.text
+		}
+	}
+
+	return turns
+}
+
+// paragraph текст абзаца с нормализованными пробелами и текст выделенного жирным шрифтом начала абзаца
+type paragraph struct {
+	text string
+	bold string
+}
+
+// label возвращает метку говорящего в начале абзаца и текст реплики без метки
+func (p paragraph) label() (string, string) {
+	if p.bold != "" {
+		label := p.bold
+		rest := strings.TrimPrefix(p.text, p.bold)
+		// Двоеточие может быть как внутри выделения, так и сразу после него
+		if strings.HasSuffix(label, ":") {
+			label = strings.TrimSpace(strings.TrimSuffix(label, ":"))
+		} else if strings.HasPrefix(strings.TrimSpace(rest), ":") {
+			rest = strings.TrimPrefix(strings.TrimSpace(rest), ":")
+		} else {
+			label = ""
+		}
+		if label != "" && labelRe.MatchString(label) && utf8.RuneCountInString(label) <= maxSpeakerLen {
+			return Normalize(label), strings.TrimSpace(rest)
+		}
+	}
+
+	m := initialsRe.FindStringSubmatchIndex(p.text)
+	if m == nil {
+		m = roleRe.FindStringSubmatchIndex(p.text)
+	}
+	if m == nil {
+		return "", ""
+	}
+	label := p.text[m[2]:m[3]]
+	if utf8.RuneCountInString(label) > maxSpeakerLen {
+		return "", ""
+	}
+	return Normalize(label), strings.TrimSpace(p.text[m[1]:])
+}
+
+// paragraphs возвращает абзацы контента
+func paragraphs(content string) []paragraph {
+	var result []paragraph
+	var builder, bold strings.Builder
+	// inBold абзац начинается с выделения жирным шрифтом, текст которого еще читается
+	inBold, boldDone := false, false
+
+	flush := func() {
+		if text := normalizeSpace(builder.String()); text != "" {
+			par := paragraph{text: text}
+			if boldDone || inBold {
+				par.bold = normalizeSpace(bold.String())
+			}
+			result = append(result, par)
+		}
+		builder.Reset()
+		bold.Reset()
+		inBold, boldDone = false, false
+	}
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			flush()
+			return result
+		case html.TextToken:
+			text := z.Text()
+			builder.Write(text)
+			if inBold {
+				bold.Write(text)
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch {
+			case blockTags[string(name)]:
+				flush()
+			case string(name) == "b" || string(name) == "strong":
+				if tt == html.StartTagToken && !boldDone && strings.TrimSpace(builder.String()) == "" {
+					inBold = true
+				} else if tt == html.EndTagToken && inBold {
+					inBold, boldDone = false, true
+				}
+			}
+		}
+	}
+}
+
+// normalizeSpace заменяет последовательности пробельных символов одиночными пробелами
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,63 @@
+package transcript
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"initials and surname", "<p>В.Путин: Добрый день, уважаемые коллеги!</p>", "В.Путин"},
+		{"two initials", "<p>М.В.Захарова: Начну с темы Украины.</p>", "М.В.Захарова"},
+		{"spaced initials", "<p>В. В. Путин: Спасибо.</p>", "В.В.Путин"},
+		{"latin initials", "<p>S.Lavrov: Thank you.</p>", "S.Lavrov"},
+		{"double surname", "<p>Н.Римский-Корсаков: Да.</p>", "Н.Римский-Корсаков"},
+		{"bold question", "<p><b>Вопрос:</b> Как вы оцениваете переговоры?</p>", "Вопрос"},
+		{"bold with colon outside", "<p><strong>Вопрос</strong>: Как вы оцениваете переговоры?</p>", "Вопрос"},
+		{"bold role", "<p><b>Председательствующий:</b> Слово предоставляется...</p>", "Председательствующий"},
+		{"bold initials", "<p><b>Д.Песков:</b> Нет.</p>", "Д.Песков"},
+		{"plain question", "<p>Вопрос: что будет дальше, остается открытым.</p>", "Вопрос"},
+		{"plain answer", "<p>Answer: yes.</p>", "Answer"},
+		{"plain role prefix", "<p>Вопросы: что будет дальше.</p>", ""},
+		{"example", "<p>Например: в прошлом году объем торговли вырос.</p>", ""},
+		{"reference", "<p>Справка: министерство создано в 1802 году.</p>", ""},
+		{"plain capitalised words", "<p>Главное управление: итоги года.</p>", ""},
+		{"bold not at start", "<p>Как сказал <b>Вопрос:</b> нет.</p>", ""},
+		{"bold without colon", "<p><b>Важно</b> отметить следующее.</p>", ""},
+		{"bold sentence", "<p><b>Это очень длинное выделенное предложение:</b> текст.</p>", ""},
+		{"no colon", "<p>В.Путин провел совещание.</p>", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Label(tt.content); got != tt.want {
+				t.Errorf("Label(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := `<p>Вступительная часть без говорящего.</p>
+<p><b>Вопрос:</b> Как вы оцениваете итоги?</p>
+<p>М.В.Захарова: Итоги положительные.</p>
+<p>Например: объем торговли вырос.</p>
+<p>Справка: данные за год.</p>
+<p><strong>Вопрос</strong>: Спасибо.</p>
+<p>Ответ: Пожалуйста.</p>`
+
+	want := []Turn{
+		{Speaker: "Вопрос", Text: "Как вы оцениваете итоги?", Order: 1},
+		{Speaker: "М.В.Захарова", Text: "Итоги положительные.\nНапример: объем торговли вырос.\nСправка: данные за год.", Order: 2},
+		{Speaker: "Вопрос", Text: "Спасибо.", Order: 3},
+		{Speaker: "Ответ", Text: "Пожалуйста.", Order: 4},
+	}
+	if got := Parse(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package watermark
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+)
+
+// Watermark состояние опроса ленты: самая поздняя дата публикации среди сохраненных записей,
+// номер цикла опроса и отпечатки сохраненных записей, которые были в ленте при последнем опросе
+type Watermark struct {
+	Key          string            `json:"key"`
+	Published    time.Time         `json:"published"`
+	Cycle        int               `json:"cycle"`
+	Fingerprints map[string]string `json:"fingerprints"`
+	Updated      time.Time         `json:"updated"`
+}
+
+// Store хранилище состояний опроса лент
+type Store interface {
+	// Load возвращает сохраненное состояние по ключу или nil, если состояния нет
+	Load(key string) (*Watermark, error)
+	Save(wm *Watermark) error
+}
+
+// FileStore хранит состояние каждой ленты в отдельном JSON файле в каталоге dir
+type FileStore struct {
+	dir string
+}
+
+var _ Store = &FileStore{}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create watermark dir %v: %v", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (fs *FileStore) Load(key string) (*Watermark, error) {
+	var wm Watermark
+	ok, err := jsonfile.Read(filepath.Join(fs.dir, key+".json"), &wm)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &wm, nil
+}
+
+func (fs *FileStore) Save(wm *Watermark) error {
+	return jsonfile.Write(filepath.Join(fs.dir, wm.Key+".json"), wm)
+}
+
+// Key формирует ключ состояния ленты из ресурса, языка и адреса ленты
+func Key(resourceID int, lang string, url string) string {
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return fmt.Sprintf("%d-%s-%08x", resourceID, lang, h.Sum32())
+}
+
+// Fingerprint отпечаток записи ленты. Меняется, если в ленте изменились
+// заголовок, описание, контент или даты публикации и обновления записи.
+func Fingerprint(e feed.Entry) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s",
+		e.Url, e.Title, e.Summary, e.Content, formatTime(e.Published), formatTime(e.Updated))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Filter пропускает записи, которые уже были получены при прошлом опросе ленты и с тех пор не изменились
+type Filter struct {
+	store         Store
+	fullScanEvery int
+	mu            sync.Mutex // Состояние ленты меняют парсер при опросе и воркеры после сохранения записей
+}
+
+// NewFilter создает фильтр. Каждый fullScanEvery-й цикл опроса все записи ленты передаются
+// на обработку без фильтрации, 0 — полный просмотр никогда не выполняется.
+func NewFilter(store Store, fullScanEvery int) *Filter {
+	return &Filter{
+		store:         store,
+		fullScanEvery: fullScanEvery,
+	}
+}
+
+// Apply возвращает записи, которые нужно обработать, и количество пропущенных записей.
+// Запись пропускается, если она опубликована не позже сохраненной отметки
+// и ее отпечаток совпадает с отпечатком, сохраненным методом Commit.
+// Apply сохраняет только номер цикла опроса и удаляет отпечатки записей, которых больше нет в ленте.
+func (f *Filter) Apply(key string, entries []feed.Entry) ([]feed.Entry, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wm, err := f.load(key)
+	if err != nil {
+		return entries, 0, err
+	}
+
+	fullScan := wm.Cycle == 0
+	wm.Cycle++
+	if f.fullScanEvery > 0 && wm.Cycle%f.fullScanEvery == 0 {
+		fullScan = true
+	}
+
+	var result []feed.Entry
+	fingerprints := make(map[string]string, len(entries))
+
+	for _, e := range entries {
+		fp, ok := wm.Fingerprints[e.Url]
+		if ok {
+			fingerprints[e.Url] = fp
+		}
+
+		if !fullScan && e.Published != nil && !e.Published.After(wm.Published) && fp == Fingerprint(e) {
+			continue
+		}
+		result = append(result, e)
+	}
+
+	wm.Fingerprints = fingerprints
+	wm.Updated = time.Now()
+
+	if err := f.store.Save(wm); err != nil {
+		return result, len(entries) - len(result), err
+	}
+
+	return result, len(entries) - len(result), nil
+}
+
+// Commit отмечает запись e, полученную из ленты, сохраненной: запоминает ее отпечаток и сдвигает отметку
+// на дату ее публикации. Вызывается после сохранения записи, поэтому запись, которую сохранить не удалось,
+// при следующем опросе снова передается на обработку.
+func (f *Filter) Commit(key string, e feed.Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wm, err := f.load(key)
+	if err != nil {
+		return err
+	}
+
+	if wm.Fingerprints == nil {
+		wm.Fingerprints = make(map[string]string)
+	}
+	wm.Fingerprints[e.Url] = Fingerprint(e)
+	if e.Published != nil && e.Published.After(wm.Published) {
+		wm.Published = *e.Published
+	}
+	wm.Updated = time.Now()
+
+	return f.store.Save(wm)
+}
+
+// load возвращает сохраненное состояние ленты или новое состояние, если ленту еще не опрашивали
+func (f *Filter) load(key string) (*Watermark, error) {
+	wm, err := f.store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if wm == nil {
+		wm = &Watermark{Key: key}
+	}
+	return wm, nil
+}
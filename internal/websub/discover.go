@@ -0,0 +1,63 @@
+package websub
+
+import (
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/atom"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// Discover возвращает адрес хаба и адрес фида rel="self", объявленные в ленте ссылками atom:link
+func Discover(gf *gofeed.Feed) (hub string, self string) {
+	if gf == nil {
+		return "", ""
+	}
+	for _, key := range []string{"atom", "atom10", "atom03"} {
+		for _, l := range gf.Extensions[key]["link"] {
+			switch l.Attrs["rel"] {
+			case "hub":
+				if hub == "" {
+					hub = l.Attrs["href"]
+				}
+			case "self":
+				if self == "" {
+					self = l.Attrs["href"]
+				}
+			}
+		}
+	}
+	return hub, self
+}
+
+// AtomTranslator переводит ленты Atom как gofeed.DefaultAtomTranslator и сохраняет ссылки
+// rel="hub" и rel="self" в расширениях ленты, как у лент RSS, чтобы их находил Discover
+type AtomTranslator struct {
+	gofeed.DefaultAtomTranslator
+}
+
+func (t *AtomTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultAtomTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	af, ok := feed.(*atom.Feed)
+	if !ok {
+		return result, nil
+	}
+
+	for _, l := range af.Links {
+		if l.Rel != "hub" && l.Rel != "self" {
+			continue
+		}
+		if result.Extensions == nil {
+			result.Extensions = ext.Extensions{}
+		}
+		if result.Extensions["atom"] == nil {
+			result.Extensions["atom"] = map[string][]ext.Extension{}
+		}
+		result.Extensions["atom"]["link"] = append(result.Extensions["atom"]["link"], ext.Extension{
+			Name:  "link",
+			Attrs: map[string]string{"rel": l.Rel, "href": l.Href},
+		})
+	}
+	return result, nil
+}
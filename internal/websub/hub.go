@@ -0,0 +1,304 @@
+package websub
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/terratensor/feed-parser/internal/lib/jsonfile"
+)
+
+const (
+	// defaultLease срок подписки, если подписчик его не указал
+	defaultLease = 10 * 24 * time.Hour
+	// maxLease максимальный срок подписки
+	maxLease = 30 * 24 * time.Hour
+)
+
+// Subscription подписка на фид
+type Subscription struct {
+	Topic    string    `json:"topic"`
+	Callback string    `json:"callback"`
+	Secret   string    `json:"secret,omitempty"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Content возвращает содержимое фида topic и его тип, ok false — хаб не обслуживает этот фид
+type Content func(topic string) (data []byte, contentType string, ok bool, err error)
+
+// Hub минимальный хаб WebSub. Подписки подтверждаются запросом к адресу подписчика,
+// при публикации содержимое фида отправляется всем действующим подписчикам.
+type Hub struct {
+	self    string // Адрес хаба для заголовка Link rel="hub"
+	content Content
+	state   string // Файл для сохранения подписок, пустая строка — подписки хранятся только в памяти
+	client  *http.Client
+
+	mu   sync.Mutex
+	subs map[string]map[string]Subscription // подписки по фиду и адресу подписчика
+}
+
+// NewHub создает хаб с адресом self. Подписки загружаются из файла state, если он задан.
+func NewHub(self string, content Content, state string) (*Hub, error) {
+	h := &Hub{
+		self:    self,
+		content: content,
+		state:   state,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		subs:    make(map[string]map[string]Subscription),
+	}
+	if state != "" {
+		var subs []Subscription
+		if _, err := jsonfile.Read(state, &subs); err != nil {
+			return nil, err
+		}
+		for _, s := range subs {
+			h.add(s)
+		}
+	}
+	return h, nil
+}
+
+// ServeHTTP обрабатывает запросы подписки, отписки и публикации
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	switch mode := r.PostForm.Get("hub.mode"); mode {
+	case "subscribe", "unsubscribe":
+		h.handleSubscription(w, r, mode)
+	case "publish":
+		h.handlePublish(w, r)
+	default:
+		http.Error(w, fmt.Sprintf("unsupported hub.mode %q", mode), http.StatusBadRequest)
+	}
+}
+
+func (h *Hub) handleSubscription(w http.ResponseWriter, r *http.Request, mode string) {
+	topic := r.PostForm.Get("hub.topic")
+	callback := r.PostForm.Get("hub.callback")
+	if u, err := url.ParseRequestURI(callback); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		http.Error(w, "invalid hub.callback", http.StatusBadRequest)
+		return
+	}
+	if _, _, ok, _ := h.content(topic); !ok {
+		http.Error(w, "unknown hub.topic", http.StatusNotFound)
+		return
+	}
+
+	lease := defaultLease
+	if v := r.PostForm.Get("hub.lease_seconds"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			lease = time.Duration(n) * time.Second
+		}
+	}
+	if lease > maxLease {
+		lease = maxLease
+	}
+
+	s := Subscription{Topic: topic, Callback: callback, Secret: r.PostForm.Get("hub.secret")}
+	w.WriteHeader(http.StatusAccepted)
+
+	// Намерение подписчика подтверждается асинхронно
+	go func() {
+		if err := h.verify(mode, s, lease); err != nil {
+			log.Printf("websub: %v intent of %v for %v is not verified: %v", mode, callback, topic, err)
+			return
+		}
+		if mode == "subscribe" {
+			s.Expires = time.Now().Add(lease)
+			h.add(s)
+		} else {
+			h.remove(s)
+		}
+		h.save()
+		log.Printf("websub: %v %v for %v", mode, callback, topic)
+	}()
+}
+
+// verify запрашивает подтверждение подписки у подписчика: подписчик должен вернуть hub.challenge
+func (h *Hub) verify(mode string, s Subscription, lease time.Duration) error {
+	challenge, err := randomChallenge()
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(s.Callback)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("hub.mode", mode)
+	q.Set("hub.topic", s.Topic)
+	q.Set("hub.challenge", challenge)
+	if mode == "subscribe" {
+		q.Set("hub.lease_seconds", strconv.Itoa(int(lease.Seconds())))
+	}
+	u.RawQuery = q.Encode()
+
+	resp, err := h.client.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	if string(bytes.TrimSpace(body)) != challenge {
+		return fmt.Errorf("challenge mismatch")
+	}
+	return nil
+}
+
+func (h *Hub) handlePublish(w http.ResponseWriter, r *http.Request) {
+	topics := r.PostForm["hub.url"]
+	if len(topics) == 0 {
+		topics = r.PostForm["hub.topic"]
+	}
+	if len(topics) == 0 {
+		http.Error(w, "hub.url is not set", http.StatusBadRequest)
+		return
+	}
+
+	for _, topic := range topics {
+		if _, _, ok, _ := h.content(topic); !ok {
+			http.Error(w, "unknown hub.url "+topic, http.StatusNotFound)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	for _, topic := range topics {
+		go h.Distribute(topic)
+	}
+}
+
+// Distribute отправляет текущее содержимое фида topic всем действующим подписчикам
+func (h *Hub) Distribute(topic string) {
+	data, contentType, ok, err := h.content(topic)
+	if err != nil || !ok {
+		log.Printf("websub: failed to read content of %v: %v", topic, err)
+		return
+	}
+
+	for _, s := range h.active(topic) {
+		if err := h.deliver(s, data, contentType); err != nil {
+			log.Printf("websub: failed to deliver %v to %v: %v", topic, s.Callback, err)
+		}
+	}
+}
+
+// deliver отправляет содержимое фида подписчику, тело подписывается секретом подписки
+func (h *Hub) deliver(s Subscription, data []byte, contentType string) error {
+	req, err := http.NewRequest(http.MethodPost, s.Callback, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, h.self))
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="self"`, s.Topic))
+	if s.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(data)
+		req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusGone {
+		// Подписчик больше не принимает уведомления
+		h.remove(s)
+		h.save()
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	return nil
+}
+
+// active возвращает действующие подписки на фид и удаляет истекшие
+func (h *Hub) active(topic string) []Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var subs []Subscription
+	for callback, s := range h.subs[topic] {
+		if time.Now().After(s.Expires) {
+			delete(h.subs[topic], callback)
+			continue
+		}
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+func (h *Hub) add(s Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[s.Topic] == nil {
+		h.subs[s.Topic] = make(map[string]Subscription)
+	}
+	h.subs[s.Topic][s.Callback] = s
+}
+
+func (h *Hub) remove(s Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[s.Topic], s.Callback)
+}
+
+// save сохраняет подписки в файл state
+func (h *Hub) save() {
+	if h.state == "" {
+		return
+	}
+
+	h.mu.Lock()
+	var subs []Subscription
+	for _, byCallback := range h.subs {
+		for _, s := range byCallback {
+			subs = append(subs, s)
+		}
+	}
+	h.mu.Unlock()
+
+	if err := jsonfile.Write(h.state, subs); err != nil {
+		log.Printf("websub: failed to save subscriptions: %v", err)
+	}
+}
+
+func randomChallenge() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTopic = "https://rss.example/rss.xml"
+
+var testFeed = []byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>t</title></channel></rss>`)
+
+func testContent(topic string) ([]byte, string, bool, error) {
+	if topic != testTopic {
+		return nil, "", false, nil
+	}
+	return testFeed, "application/rss+xml", true, nil
+}
+
+// push содержимое, которое хаб отправил подписчику
+type push struct {
+	body   []byte
+	header http.Header
+}
+
+// testSubscriber подписчик, который подтверждает намерения и принимает содержимое фидов
+type testSubscriber struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	verified   []url.Values
+	pushes     []push
+	badAnswer  bool // Отвечать на подтверждение неверным hub.challenge
+	pushStatus int  // Код ответа на содержимое фида
+}
+
+func newTestSubscriber(t *testing.T) *testSubscriber {
+	s := &testSubscriber{pushStatus: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			s.verified = append(s.verified, q)
+			if s.badAnswer {
+				w.Write([]byte("wrong"))
+				return
+			}
+			w.Write([]byte(q.Get("hub.challenge")))
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			s.pushes = append(s.pushes, push{body: body, header: r.Header.Clone()})
+			w.WriteHeader(s.pushStatus)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testSubscriber) received() []push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]push(nil), s.pushes...)
+}
+
+func (s *testSubscriber) verifications() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.verified...)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %v", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func postForm(t *testing.T, hub *httptest.Server, form url.Values) int {
+	t.Helper()
+	resp, err := http.PostForm(hub.URL, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func subscribe(t *testing.T, hub *httptest.Server, callback string, secret string) {
+	t.Helper()
+	form := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {testTopic},
+		"hub.callback":      {callback},
+		"hub.lease_seconds": {"3600"},
+	}
+	if secret != "" {
+		form.Set("hub.secret", secret)
+	}
+	if status := postForm(t, hub, form); status != http.StatusAccepted {
+		t.Fatalf("subscribe status = %d, want %d", status, http.StatusAccepted)
+	}
+}
+
+func newTestHub(t *testing.T, state string) (*Hub, *httptest.Server) {
+	h, err := NewHub("https://hub.example/websub", testContent, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return h, srv
+}
+
+func TestHubSubscribeAndPublish(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "subs.json")
+	h, hub := newTestHub(t, state)
+	sub := newTestSubscriber(t)
+
+	subscribe(t, hub, sub.URL+"/cb", "s3cret")
+	waitFor(t, "subscription", func() bool { return len(h.active(testTopic)) == 1 })
+
+	v := sub.verifications()
+	if len(v) != 1 || v[0].Get("hub.mode") != "subscribe" || v[0].Get("hub.topic") != testTopic || v[0].Get("hub.lease_seconds") != "3600" {
+		t.Fatalf("verification request = %v", v)
+	}
+	if s := h.active(testTopic)[0]; time.Until(s.Expires) > time.Hour || time.Until(s.Expires) < 59*time.Minute {
+		t.Errorf("subscription expires at %v, want in an hour", s.Expires)
+	}
+
+	status := postForm(t, hub, url.Values{"hub.mode": {"publish"}, "hub.url": {testTopic}})
+	if status != http.StatusAccepted {
+		t.Fatalf("publish status = %d, want %d", status, http.StatusAccepted)
+	}
+	waitFor(t, "content distribution", func() bool { return len(sub.received()) == 1 })
+
+	p := sub.received()[0]
+	if string(p.body) != string(testFeed) {
+		t.Errorf("pushed body = %q", p.body)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(testFeed)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); p.header.Get("X-Hub-Signature") != want {
+		t.Errorf("X-Hub-Signature = %q, want %q", p.header.Get("X-Hub-Signature"), want)
+	}
+	links := strings.Join(p.header.Values("Link"), ", ")
+	if !strings.Contains(links, `<https://hub.example/websub>; rel="hub"`) || !strings.Contains(links, `<`+testTopic+`>; rel="self"`) {
+		t.Errorf("Link = %q", links)
+	}
+	if p.header.Get("Content-Type") != "application/rss+xml" {
+		t.Errorf("Content-Type = %q", p.header.Get("Content-Type"))
+	}
+
+	// Подписки сохраняются между перезапусками хаба
+	restarted, err := NewHub("https://hub.example/websub", testContent, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.active(testTopic)) != 1 {
+		t.Error("subscription is not restored from state")
+	}
+}
+
+func TestHubRejectsUnverifiedIntent(t *testing.T) {
+	h, hub := newTestHub(t, "")
+	sub := newTestSubscriber(t)
+	sub.badAnswer = true
+
+	subscribe(t, hub, sub.URL+"/cb", "")
+	waitFor(t, "verification request", func() bool { return len(sub.verifications()) == 1 })
+	time.Sleep(50 * time.Millisecond)
+
+	if len(h.active(testTopic)) != 0 {
+		t.Error("subscription with wrong challenge is added")
+	}
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	h, hub := newTestHub(t, "")
+	sub := newTestSubscriber(t)
+
+	subscribe(t, hub, sub.URL+"/cb", "")
+	waitFor(t, "subscription", func() bool { return len(h.active(testTopic)) == 1 })
+
+	postForm(t, hub, url.Values{"hub.mode": {"unsubscribe"}, "hub.topic": {testTopic}, "hub.callback": {sub.URL + "/cb"}})
+	waitFor(t, "unsubscription", func() bool { return len(h.active(testTopic)) == 0 })
+}
+
+func TestHubUnknownTopic(t *testing.T) {
+	_, hub := newTestHub(t, "")
+	sub := newTestSubscriber(t)
+
+	form := url.Values{"hub.mode": {"subscribe"}, "hub.topic": {"https://other.example/rss.xml"}, "hub.callback": {sub.URL}}
+	if status := postForm(t, hub, form); status != http.StatusNotFound {
+		t.Errorf("subscribe status = %d, want %d", status, http.StatusNotFound)
+	}
+	if status := postForm(t, hub, url.Values{"hub.mode": {"publish"}, "hub.url": {"https://other.example/rss.xml"}}); status != http.StatusNotFound {
+		t.Errorf("publish status = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestHubRemovesGoneSubscriber(t *testing.T) {
+	h, _ := newTestHub(t, "")
+	sub := newTestSubscriber(t)
+	sub.pushStatus = http.StatusGone
+
+	h.add(Subscription{Topic: testTopic, Callback: sub.URL + "/cb", Expires: time.Now().Add(time.Hour)})
+	h.Distribute(testTopic)
+
+	if len(sub.received()) != 1 {
+		t.Fatalf("pushes = %d, want 1", len(sub.received()))
+	}
+	if len(h.active(testTopic)) != 0 {
+		t.Error("subscription is not removed after 410 Gone")
+	}
+}
+
+func TestHubSkipsExpiredLease(t *testing.T) {
+	h, _ := newTestHub(t, "")
+	sub := newTestSubscriber(t)
+
+	h.add(Subscription{Topic: testTopic, Callback: sub.URL + "/expired", Expires: time.Now().Add(-time.Minute)})
+	h.add(Subscription{Topic: testTopic, Callback: sub.URL + "/active", Expires: time.Now().Add(time.Hour)})
+	h.Distribute(testTopic)
+
+	if len(sub.received()) != 1 {
+		t.Fatalf("pushes = %d, want 1 to the active subscription", len(sub.received()))
+	}
+	if subs := h.active(testTopic); len(subs) != 1 || !strings.HasSuffix(subs[0].Callback, "/active") {
+		t.Errorf("active subscriptions = %v", subs)
+	}
+}
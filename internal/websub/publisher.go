@@ -0,0 +1,30 @@
+// Package websub реализует протокол WebSub (PubSubHubbub): уведомление хабов об изменении фидов
+// и минимальный хаб, который принимает подписки и рассылает подписчикам новое содержимое фидов
+package websub
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Publish сообщает хабу hub, что содержимое фида topic изменилось
+func Publish(client *http.Client, hub string, topic string) error {
+	form := url.Values{}
+	form.Set("hub.mode", "publish")
+	form.Set("hub.url", topic)
+
+	resp, err := client.Post(hub, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to ping hub %v: %v", hub, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hub %v: unexpected status %v for %v", hub, resp.Status, topic)
+	}
+	return nil
+}
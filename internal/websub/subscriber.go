@@ -0,0 +1,238 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxPushSize максимальный размер содержимого, присланного хабом
+	maxPushSize = 10 << 20
+	// pendingTimeout время ожидания подтверждения подписки хабом, после которого запрос повторяется
+	pendingTimeout = time.Hour
+)
+
+// PushHandler обрабатывает содержимое фида, присланное хабом
+type PushHandler func(topic string, body []byte)
+
+type upstream struct {
+	hub       string
+	topic     string
+	secret    string
+	handler   PushHandler
+	requested time.Time
+	expires   time.Time // Окончание подписки, нулевое — подписка еще не подтверждена
+}
+
+// Subscriber подписывается на обновления лент через хабы WebSub и принимает присланное содержимое
+// по адресу callback/<id>, где id определяется адресом фида
+type Subscriber struct {
+	callback    string
+	secret      string
+	lease       time.Duration
+	renewBefore time.Duration
+	client      *http.Client
+
+	mu   sync.Mutex
+	subs map[string]*upstream
+}
+
+// NewSubscriber создает подписчика. callback — публичный адрес, по которому хабы обращаются к службе,
+// secret — ключ, из которого получаются секреты подписок для проверки подписи содержимого,
+// без ключа присланное хабами содержимое не принимается,
+// lease — запрашиваемый срок подписки, подписка продлевается, когда до окончания остается меньше renewBefore.
+func NewSubscriber(callback string, secret string, lease time.Duration, renewBefore time.Duration) *Subscriber {
+	return &Subscriber{
+		callback:    strings.TrimRight(callback, "/"),
+		secret:      secret,
+		lease:       lease,
+		renewBefore: renewBefore,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		subs:        make(map[string]*upstream),
+	}
+}
+
+// Ensure подписывается на фид topic через хаб hub, если подписки еще нет, она не подтверждена
+// дольше часа или скоро закончится. Вызывается при каждом опросе ленты.
+func (s *Subscriber) Ensure(hub string, topic string, handler PushHandler) error {
+	id := topicID(topic)
+
+	s.mu.Lock()
+	u, ok := s.subs[id]
+	now := time.Now()
+	if ok && u.hub == hub {
+		if !u.expires.IsZero() && u.expires.Sub(now) > s.renewBefore {
+			s.mu.Unlock()
+			return nil
+		}
+		if u.expires.IsZero() && now.Sub(u.requested) < pendingTimeout {
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	u = &upstream{hub: hub, topic: topic, secret: s.topicSecret(topic), handler: handler, requested: now}
+	if prev, ok := s.subs[id]; ok && prev.hub == hub {
+		// Действующая подписка сохраняется до подтверждения продления
+		u.expires = prev.expires
+	}
+	s.subs[id] = u
+	s.mu.Unlock()
+
+	return s.request(u, id)
+}
+
+// request отправляет хабу запрос подписки
+func (s *Subscriber) request(u *upstream, id string) error {
+	form := url.Values{}
+	form.Set("hub.mode", "subscribe")
+	form.Set("hub.topic", u.topic)
+	form.Set("hub.callback", s.callback+"/"+id)
+	form.Set("hub.lease_seconds", strconv.Itoa(int(s.lease.Seconds())))
+	if u.secret != "" {
+		form.Set("hub.secret", u.secret)
+	}
+
+	resp, err := s.client.PostForm(u.hub, form)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %v via %v: %v", u.topic, u.hub, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hub %v rejected subscription to %v: %v", u.hub, u.topic, resp.Status)
+	}
+	log.Printf("websub: subscription to %v requested via %v", u.topic, u.hub)
+	return nil
+}
+
+// ServeHTTP отвечает на запросы подтверждения подписки и принимает содержимое фидов
+func (s *Subscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+
+	s.mu.Lock()
+	u, ok := s.subs[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.verify(w, r, id, u)
+	case http.MethodPost:
+		s.receive(w, r, u)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify подтверждает хабу намерение подписаться, отписки служба не запрашивает
+func (s *Subscriber) verify(w http.ResponseWriter, r *http.Request, id string, u *upstream) {
+	q := r.URL.Query()
+	switch q.Get("hub.mode") {
+	case "subscribe":
+		if q.Get("hub.topic") != u.topic {
+			http.NotFound(w, r)
+			return
+		}
+		lease := s.lease
+		if n, err := strconv.Atoi(q.Get("hub.lease_seconds")); err == nil && n > 0 {
+			lease = time.Duration(n) * time.Second
+		}
+		s.mu.Lock()
+		u.expires = time.Now().Add(lease)
+		s.mu.Unlock()
+		log.Printf("websub: subscribed to %v until %v", u.topic, u.expires.Format(time.RFC3339))
+		w.Write([]byte(q.Get("hub.challenge")))
+	case "denied":
+		log.Printf("websub: hub %v denied subscription to %v: %v", u.hub, u.topic, q.Get("hub.reason"))
+		s.mu.Lock()
+		delete(s.subs, id)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// receive принимает содержимое фида и передает его обработчику подписки.
+// Содержимое без подписи или с неверной подписью отбрасывается, но хабу отвечаем успехом, как требует протокол.
+func (s *Subscriber) receive(w http.ResponseWriter, r *http.Request, u *upstream) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushSize))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	// Без секрета подлинность содержимого не проверить
+	if u.secret == "" || !validSignature(r.Header.Get("X-Hub-Signature"), body, u.secret) {
+		log.Printf("websub: invalid signature of content for %v, ignored", u.topic)
+		return
+	}
+	go u.handler(u.topic, body)
+}
+
+// topicSecret секрет подписки на фид, получается из общего ключа, чтобы не хранить секреты подписок
+func (s *Subscriber) topicSecret(topic string) string {
+	if s.secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(topic))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// topicID короткий идентификатор фида для адреса callback
+func topicID(topic string) string {
+	h := fnv.New64a()
+	h.Write([]byte(topic))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// validSignature проверяет заголовок X-Hub-Signature вида method=signature
+func validSignature(header string, body []byte, secret string) bool {
+	method, sig, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var h func() hash.Hash
+	switch method {
+	case "sha1":
+		h = sha1.New
+	case "sha256":
+		h = sha256.New
+	case "sha384":
+		h = sha512.New384
+	case "sha512":
+		h = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
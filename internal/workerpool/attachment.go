@@ -0,0 +1,79 @@
+package workerpool
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/terratensor/feed-parser/internal/attachment"
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/splitter"
+)
+
+// AttachmentChunks загружает документы PDF и DOCX из медиа записи, извлекает из них текст
+// и разбивает его на фрагменты. Фрагменты ссылаются на адрес записи,
+// адрес документа сохраняется в поле Attachment, название документа — в поле Section.
+func AttachmentChunks(ctx context.Context, e *feed.Entry, chunker splitter.Chunker, cfg *config.Config) []feed.Entry {
+	if !cfg.Attachments.Enabled {
+		return nil
+	}
+
+	var chunks []feed.Entry
+	count := 0
+	for _, m := range e.Media {
+		if m.Type != feed.MediaDocument || !attachment.Supported(m.Url, m.MimeType) {
+			continue
+		}
+		if cfg.Attachments.MaxCount > 0 && count >= cfg.Attachments.MaxCount {
+			break
+		}
+		count++
+
+		data, err := attachment.Download(ctx, m.Url, cfg.UserAgent, cfg.Attachments.MaxSize, cfg.Attachments.Timeout)
+		if err != nil {
+			log.Printf("failed to download attachment %v of %v: %v", m.Url, e.Url, err)
+			continue
+		}
+		paragraphs, err := attachment.Extract(data)
+		if err != nil {
+			log.Printf("failed to extract text from attachment %v of %v: %v", m.Url, e.Url, err)
+			continue
+		}
+
+		var builder strings.Builder
+		for _, p := range paragraphs {
+			builder.WriteString("<p>")
+			builder.WriteString(html.EscapeString(p))
+			builder.WriteString("</p>")
+		}
+
+		ae := *e
+		ae.Content = builder.String()
+		ae.Media = nil
+		for _, chunk := range chunker.SplitEntry(ctx, ae) {
+			chunk.Attachment = m.Url
+			if m.Caption != "" {
+				chunk.Section = m.Caption
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	return chunks
+}
+
+// appendAttachments добавляет фрагменты вложений после фрагментов записи
+// и пересчитывает номера и общее количество фрагментов
+func appendAttachments(chunks []feed.Entry, attachments []feed.Entry) []feed.Entry {
+	if len(attachments) == 0 {
+		return chunks
+	}
+	chunks = append(chunks, attachments...)
+	for n := range chunks {
+		chunks[n].Chunk = n + 1
+		chunks[n].ChunkTotal = len(chunks)
+	}
+	return chunks
+}
@@ -0,0 +1,29 @@
+package workerpool
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/terratensor/feed-parser/internal/dedup"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// MarkDuplicate вычисляет отпечаток записи и, если задан индекс отпечатков, ищет запись,
+// копией которой является e. Адрес найденной записи сохраняется в поле DuplicateOf.
+func MarkDuplicate(e *feed.Entry, index *dedup.Index, m *metrics.Metrics) {
+	e.Fingerprint = dedup.Fingerprint(*e)
+	if index == nil {
+		return
+	}
+
+	e.DuplicateOf = index.Match(*e)
+	if e.DuplicateOf == "" {
+		return
+	}
+
+	log.Printf("entry %v is a near-duplicate of %v", e.Url, e.DuplicateOf)
+	if m != nil {
+		m.DuplicatesFound.WithLabelValues(strconv.Itoa(e.ResourceID)).Inc()
+	}
+}
@@ -0,0 +1,23 @@
+package workerpool
+
+import (
+	"context"
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/events"
+)
+
+// publishEvent публикует событие, подготовленное до записи в мантикору. Если событие не удалось записать
+// в журнал, отметка о нем остается, и служба опубликует его при восстановлении.
+func publishEvent(p *events.Pending) {
+	if err := p.Publish(context.Background()); err != nil {
+		log.Printf("failed to publish event, it will be recovered later: %v", err)
+	}
+}
+
+// cancelEvent удаляет отметку о подготовленном событии, если запись не изменилась в мантикоре
+func cancelEvent(p *events.Pending) {
+	if err := p.Cancel(); err != nil {
+		log.Printf("failed to cancel pending event: %v", err)
+	}
+}
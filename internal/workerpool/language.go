@@ -0,0 +1,37 @@
+package workerpool
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/langdetect"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+	"github.com/terratensor/feed-parser/internal/metrics"
+)
+
+// DetectLanguage определяет язык записи по заголовку и контенту и сохраняет его в записи.
+// Если уверенно определенный язык не совпадает с языком ленты, то увеличивает счетчик
+// несовпадений, а при включенной замене записывает определенный язык в поле Language.
+func DetectLanguage(e *feed.Entry, cfg *config.Config, m *metrics.Metrics) {
+	if !cfg.Language.Detect {
+		return
+	}
+
+	result := langdetect.Detect(e.Title + "\n" + striphtml.StripHtmlTags(e.Content))
+	e.DetectedLanguage = result.Lang
+	e.LanguageConfidence = result.Confidence
+
+	if result.Lang == "" || result.Lang == e.Language || result.Confidence < cfg.Language.MinConfidence {
+		return
+	}
+
+	log.Printf("language mismatch for %v: feed %v, detected %v (%v)", e.Url, e.Language, result.Lang, result.Confidence)
+	if m != nil {
+		m.LanguageMismatch.WithLabelValues(strconv.Itoa(e.ResourceID), e.Language, result.Lang).Inc()
+	}
+	if cfg.Language.Override {
+		e.Language = result.Lang
+	}
+}
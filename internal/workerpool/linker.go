@@ -0,0 +1,26 @@
+package workerpool
+
+import (
+	"context"
+	"log"
+
+	"github.com/terratensor/feed-parser/internal/config"
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/linker"
+)
+
+// LinkTranslations присваивает записи идентификатор группы переводов, если связывание включено
+func LinkTranslations(e *feed.Entry, store feed.StorageInterface, cfg *config.Config) {
+	if !cfg.Linker.Enabled {
+		return
+	}
+
+	l, err := linker.New(store, cfg)
+	if err != nil {
+		log.Printf("failed to create linker: %v", err)
+		return
+	}
+	if err = l.Link(context.Background(), e); err != nil {
+		log.Printf("failed to link translations of %v: %v", e.Url, err)
+	}
+}
@@ -40,6 +40,25 @@ func (p *Pool) Run() {
 	p.wg.Wait()
 }
 
+// RunStream запускает воркеры и обрабатывает задачи из канала tasks,
+// после закрытия канала дожидается завершения всех задач
+func (p *Pool) RunStream(tasks <-chan *Task) {
+	for i := 0; i < p.concurrency; i++ {
+		worker := NewWorker(p.collector, i)
+		worker.Start(&p.wg)
+	}
+
+	for i := range p.Tasks {
+		p.collector <- p.Tasks[i]
+	}
+	for task := range tasks {
+		p.collector <- task
+	}
+	close(p.collector)
+
+	p.wg.Wait()
+}
+
 func (p *Pool) AddTask(task *Task) {
 	p.collector <- task
 }
@@ -0,0 +1,18 @@
+package workerpool
+
+import (
+	"html"
+
+	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/lib/striphtml"
+	"github.com/terratensor/feed-parser/internal/tagger"
+)
+
+// TagEntry отмечает в записи упоминания сущностей словаря, nil — без отметки
+func TagEntry(e *feed.Entry, t *tagger.Tagger) {
+	if t == nil {
+		return
+	}
+	text := e.Title + "\n" + e.Summary + "\n" + html.UnescapeString(striphtml.StripHtmlTags(e.Content))
+	e.Tags = t.Tag(text, e.Language)
+}
@@ -11,28 +11,36 @@ import (
 
 	"github.com/terratensor/feed-parser/internal/config"
 	"github.com/terratensor/feed-parser/internal/crawler"
+	"github.com/terratensor/feed-parser/internal/dedup"
 	"github.com/terratensor/feed-parser/internal/entities/feed"
+	"github.com/terratensor/feed-parser/internal/events"
 	"github.com/terratensor/feed-parser/internal/lib/logger/sl"
+	"github.com/terratensor/feed-parser/internal/media"
 	"github.com/terratensor/feed-parser/internal/metrics"
+	"github.com/terratensor/feed-parser/internal/sanitize"
 	"github.com/terratensor/feed-parser/internal/splitter"
 	"github.com/terratensor/feed-parser/internal/storage/manticore"
+	"github.com/terratensor/feed-parser/internal/tagger"
 )
 
 /**
 Task содержит все необходимое для обработки задачи.
-Мы передаем ей Data и функцию f, которая должна быть выполнена, с помощью функции process.
-Функция f принимает Data в качестве параметра для обработки, а также храним возвращаемую ошибку
+Мы передаем ей Data, которая обрабатывается функцией process, о сохранении и обновлении
+записи process публикует события в шину events
 */
 
 type Task struct {
-	Err error
+	Err error // Ошибка, из-за которой запись не сохранена в мантикору
 	//Entries *feed.Entries
 	Data           *feed.Entry
-	f              func(interface{}) error
-	Splitter       splitter.Splitter
+	Splitter       splitter.Chunker
 	EntriesStorage *feed.Entries
 	Config         *config.Config
 	metrics        *metrics.Metrics
+	dedup          *dedup.Index
+	tagger         *tagger.Tagger
+	events         *events.Bus
+	done           func()
 }
 
 func NewTaskStorage() *feed.Entries {
@@ -49,17 +57,48 @@ func NewTaskStorage() *feed.Entries {
 	return feed.NewFeedStorage(storage)
 }
 
-func NewTask(f func(interface{}) error, data feed.Entry, splitter *splitter.Splitter, storage *feed.Entries, cfg *config.Config, metrics *metrics.Metrics) *Task {
+func NewTask(data feed.Entry, chunker splitter.Chunker, storage *feed.Entries, cfg *config.Config, metrics *metrics.Metrics) *Task {
 	return &Task{
-		f:              f,
 		Data:           &data,
-		Splitter:       *splitter,
+		Splitter:       chunker,
 		EntriesStorage: storage,
 		Config:         cfg,
 		metrics:        metrics,
 	}
 }
 
+// WithDedup задает индекс отпечатков для поиска записей-копий, nil — без поиска копий
+func (t *Task) WithDedup(index *dedup.Index) *Task {
+	t.dedup = index
+	return t
+}
+
+// WithTagger задает Tagger для отметки упоминаний сущностей, nil — без отметки
+func (t *Task) WithTagger(tg *tagger.Tagger) *Task {
+	t.tagger = tg
+	return t
+}
+
+// WithEvents задает шину, в которую публикуются события сохранения и обновления записей, nil — без событий
+func (t *Task) WithEvents(bus *events.Bus) *Task {
+	t.events = bus
+	return t
+}
+
+// WithDone задает функцию, которую воркер вызывает после обработки задачи, nil — без уведомления.
+// Если запись не удалось сохранить, ошибка доступна в поле Err.
+func (t *Task) WithDone(done func()) *Task {
+	t.done = done
+	return t
+}
+
+// finish сообщает о завершении обработки задачи
+func (t *Task) finish() {
+	if t.done != nil {
+		t.done()
+	}
+}
+
 func process(workerID int, task *Task) {
 	fmt.Printf("Worker %d processes task %v\n", workerID, task.Data.Url)
 
@@ -73,8 +112,6 @@ func process(workerID int, task *Task) {
 	cfg := task.Config
 	metrics := task.metrics
 
-	var createdEntry feed.Entry
-
 	dbe, err := store.Storage.FindAllByUrl(context.Background(), task.Data.Url)
 	if err != nil {
 		logger.Error("failed find entry by url", sl.Err(err))
@@ -83,34 +120,71 @@ func process(workerID int, task *Task) {
 	// если записи в БД нет, то создаем записи
 	if dbe == nil || len(dbe) == 0 {
 
-		e, err = visitUrl(e, cfg, metrics)
+		e, err = VisitUrl(e, cfg, metrics)
 		if err != nil {
 			log.Printf("finishing task processing without inserting data in manticoresearch, %v", err)
+			task.Err = err
 			return
 		}
+		Prepare(e)
+		DetectLanguage(e, cfg, metrics)
+		LinkTranslations(e, store.Storage, cfg)
+		MarkDuplicate(e, task.dedup, metrics)
+		TagEntry(e, task.tagger)
 
-		// разбиваем контент на части
+		// разбиваем контент на части, текст вложений добавляем отдельными фрагментами
 		splitEntries := task.Splitter.SplitEntry(context.Background(), *e)
+		splitEntries = appendAttachments(splitEntries, AttachmentChunks(context.Background(), e, task.Splitter, cfg))
+		// Событие подготавливается до записи в мантикору, чтобы не потерять его при падении процесса
+		pending, err := task.events.Prepare(events.Event{Type: events.EntryCreated, Entry: *e}, events.ChunksHash(splitEntries))
+		if err != nil {
+			log.Printf("finishing task processing without inserting data in manticoresearch, %v", err)
+			task.Err = err
+			return
+		}
 		// итерируемся по полученному срезу частей и каждую часть в БД
 		for _, splitEntry := range splitEntries {
 			err = insertNewEntry(&splitEntry, store.Storage, *logger)
 			if err != nil {
+				// Запись сохранена не полностью, событие не публикуется
+				cancelEvent(pending)
+				task.Err = err
 				return
 			}
 		}
-		createdEntry = *e
+		publishEvent(pending)
 		// Увеличиваем счетчик вставок новостей с кол-вом фрагментов
 		metrics.EntitiesInserted.WithLabelValues(e.Url, fmt.Sprintf("%d", len(splitEntries))).Inc()
 	} else {
 		if needUpdate(&dbe[0], *e) {
 			log.Printf("требуется обновление, кол-во фрагментов в БД:, %v", len(dbe))
-			e, err = visitUrl(e, cfg, metrics)
+			e, err = VisitUrl(e, cfg, metrics)
 			if err != nil {
 				log.Printf("finishing task processing without updating data in manticoresearch %v", err)
+				task.Err = err
 				return
 			}
+			Prepare(e)
+			DetectLanguage(e, cfg, metrics)
+			// Группа переводов сохраняется при обновлении записи
+			e.GroupID = dbe[0].GroupID
+			LinkTranslations(e, store.Storage, cfg)
+			MarkDuplicate(e, task.dedup, metrics)
+			TagEntry(e, task.tagger)
 
 			splitEntries := task.Splitter.SplitEntry(context.Background(), *e)
+			splitEntries = appendAttachments(splitEntries, AttachmentChunks(context.Background(), e, task.Splitter, cfg))
+
+			// Фиксируем дату публикации для ресурса МИД для соответствующих языков
+			if e.ResourceID == 2 && (e.Language == "de" || e.Language == "fr" || e.Language == "pt" || e.Language == "es") {
+				e.Published = dbe[0].Published
+			}
+			pending, err := task.events.Prepare(events.Event{Type: events.EntryUpdated, Entry: *e, Diff: events.Diff(events.FromChunks(dbe), *e)}, events.ChunksHash(splitEntries))
+			if err != nil {
+				log.Printf("finishing task processing without updating data in manticoresearch %v", err)
+				task.Err = err
+				return
+			}
 
 			for n, splitEntry := range splitEntries {
 				// Обязательно присваиваем created дату из БД, иначе будет перезаписан 0
@@ -118,32 +192,35 @@ func process(workerID int, task *Task) {
 
 				timeNow := time.Unix(time.Now().Unix(), 0)
 				splitEntry.UpdatedAt = &timeNow
-				// Фиксируем дату публикации для ресурса МИД для соответствующих языков
-				if e.ResourceID == 2 && (e.Language == "de" || e.Language == "fr" || e.Language == "pt" || e.Language == "es") {
-					e.Published = dbe[0].Published
-				}
 				// пока n меньше, чем всего фрагментов в БД, обновляем, иначе создаем новые
 				if n < len(dbe) {
 					splitEntry.ID = dbe[n].ID
 					err = updateOldEntry(&splitEntry, store.Storage, *logger)
-					if err != nil {
-						return
-					}
 				} else {
 					err = insertNewEntry(&splitEntry, store.Storage, *logger)
-					if err != nil {
-						return
-					}
+				}
+				if err != nil {
+					// Запись обновлена не полностью, событие не публикуется
+					cancelEvent(pending)
+					task.Err = err
+					return
 				}
 				// Увеличиваем счетчик обновления новостей с кол-вом фрагментов
 				metrics.EntitiesUpdated.WithLabelValues(e.Url, fmt.Sprintf("%d", len(splitEntries))).Inc()
 			}
+			// Удаляем лишние фрагменты, если после обновления их стало меньше,
+			// чтобы у оставшихся фрагментов совпадало количество chunk_total
+			for n := len(splitEntries); n < len(dbe); n++ {
+				err = store.Storage.Delete(context.Background(), dbe[n].ID)
+				if err != nil {
+					logger.Error("failed delete surplus chunk", slog.String("url", e.Url), sl.Err(err))
+				}
+			}
+			publishEvent(pending)
 		} else {
 			//log.Printf("nothing to insert, ⌛ waiting incoming tasks…")
 		}
 	}
-
-	task.Err = task.f(createdEntry)
 }
 
 func updateOldEntry(e *feed.Entry, store feed.StorageInterface, logger slog.Logger) error {
@@ -184,11 +261,11 @@ func insertNewEntry(e *feed.Entry, store feed.StorageInterface, logger slog.Logg
 	return nil
 }
 
-// visitUrl вызывает crawler, который парсит контент по ссылке,
+// VisitUrl вызывает crawler, который парсит контент по ссылке,
 // если crawler вернет ошибку, например в следствии read: connection reset by peer,
 // соединение с сайтом разорвалось, то функция возвращает запись entry без изменений,
 // если crawler вернул новую спарсенную entry (ce), то функция возвращает обновленную entry
-func visitUrl(e *feed.Entry, cfg *config.Config, metrics *metrics.Metrics) (*feed.Entry, error) {
+func VisitUrl(e *feed.Entry, cfg *config.Config, metrics *metrics.Metrics) (*feed.Entry, error) {
 
 	// получаем конфигурацию для ресурса
 	crawlerConfig, err := GetCrawlerConfigByResourceID(cfg, e.ResourceID, e.Language)
@@ -207,6 +284,13 @@ func visitUrl(e *feed.Entry, cfg *config.Config, metrics *metrics.Metrics) (*fee
 	return e, nil
 }
 
+// Prepare извлекает медиа из контента записи и приводит контент к единому виду
+// перед разбивкой на фрагменты
+func Prepare(e *feed.Entry) {
+	e.Media = feed.MergeMedia(e.Media, media.Extract(e.Content, e.Url))
+	e.Content = sanitize.HTML(e.Content, e.Url)
+}
+
 func needUpdate(dbe *feed.Entry, e feed.Entry) bool {
 	// Если заголовок в базе пустой, значит необходимо произвести обновление записи.
 	// Было замечено, что иногда с сайта МО записи попадают с пустыми значениями заголовка и контента
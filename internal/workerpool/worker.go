@@ -30,6 +30,7 @@ func (wr *Worker) Start(wg *sync.WaitGroup) {
 		defer wg.Done()
 		for task := range wr.taskChan {
 			process(wr.ID, task)
+			task.finish()
 		}
 	}()
 }
@@ -41,6 +42,7 @@ func (wr *Worker) StartBackground() {
 		select {
 		case task := <-wr.taskChan:
 			process(wr.ID, task)
+			task.finish()
 		case <-wr.quit:
 			return
 		}